@@ -1,31 +1,95 @@
 package nngo
 
+import "fmt"
+
 func (g *Graph) TopologicalSort(n *Node, visited Set[*Node], sorted *Stack[*Node], reverse bool) {
-	visited[n] = true
+	topologicalVisit(n, neighbors(reverse), visited, sorted)
+}
 
-	var neighbors [](*Node)
+// neighbors follows the outputs of a node, or its inputs in reverse.
+func neighbors(reverse bool) func(n *Node) [](*Node) {
 	if reverse {
-		neighbors = n.Inputs
-	} else {
-		neighbors = n.Outputs
+		return func(n *Node) [](*Node) {
+			return n.Inputs
+		}
+	}
+	return func(n *Node) [](*Node) {
+		return n.Outputs
+	}
+}
+
+// Schedule returns the order in which Forward evaluates nodes: everything
+// reachable from the inputs and from the listed nodes nothing feeds, like the
+// constants of a Builder. It is computed once and cached for the current
+// Inputs, Intermediates and Outputs, so a copy of the graph whose lists are
+// changed gets a schedule of its own. Rewiring nodes by hand is not noticed;
+// call Invalidate afterwards on every copy of the graph that uses them.
+func (g *Graph) Schedule() [](*Node) {
+	g.checkSchedules()
+	if g.forwardSchedule == nil {
+		g.forwardSchedule = topologicalOrder(g.leaves(), neighbors(false))
 	}
+	return g.forwardSchedule
+}
 
-	for i := range neighbors {
-		if !visited[neighbors[i]] {
-			g.TopologicalSort(neighbors[i], visited, sorted, reverse)
+// leaves returns the inputs followed by the intermediates that have no
+// inputs, which is where evaluation starts.
+func (g *Graph) leaves() [](*Node) {
+	leaves := append([](*Node){}, g.Inputs...)
+	for _, n := range g.Intermediates {
+		if len(n.Inputs) == 0 {
+			Append(&leaves, n)
 		}
 	}
+	return leaves
+}
 
-	sorted.Push(n)
+// ReverseSchedule returns the order in which Backprop visits nodes, cached
+// like Schedule.
+func (g *Graph) ReverseSchedule() [](*Node) {
+	g.checkSchedules()
+	if g.reverseSchedule == nil {
+		g.reverseSchedule = topologicalOrder(g.Outputs, neighbors(true))
+	}
+	return g.reverseSchedule
 }
 
-func (g *Graph) Forward(inputValues []float64) (err error) {
-	visited := Set[*Node]{}
-	sorted := Stack[*Node]{}
+// Invalidate drops the cached schedules so they are recomputed on next use.
+// It is needed after changing edges between nodes by hand, which the cache
+// cannot see.
+func (g *Graph) Invalidate() {
+	g.forwardSchedule = nil
+	g.reverseSchedule = nil
+}
 
-	for _, inp := range g.Inputs {
-		if !visited[inp] {
-			g.TopologicalSort(inp, visited, &sorted, false)
+// listsKey identifies the Inputs, Intermediates and Outputs of a graph by
+// where each list starts and how long it is.
+type listsKey [3]struct {
+	first **Node
+	n     int
+}
+
+// checkSchedules drops the cached schedules if they were computed for other
+// lists, as when a copy of the graph had nodes appended.
+func (g *Graph) checkSchedules() {
+	var key listsKey
+	for i, list := range [][](*Node){g.Inputs, g.Intermediates, g.Outputs} {
+		if len(list) > 0 {
+			key[i].first = &list[0]
+		}
+		key[i].n = len(list)
+	}
+	if key != g.scheduledFor {
+		g.Invalidate()
+		g.scheduledFor = key
+	}
+}
+
+func (g *Graph) Forward(inputValues []float64) (err error) {
+	if g.Strict {
+		err = g.Validate()
+		if err != nil {
+			return
 		}
 	}
 
@@ -34,23 +98,24 @@ func (g *Graph) Forward(inputValues []float64) (err error) {
 		return
 	}
 
-	for {
-		n, empty := sorted.Pop()
-		if empty {
-			break
+	for _, n := range g.Schedule() {
+		err = n.ComputeVal()
+		if err != nil {
+			return
 		}
-		n.ComputeVal()
 	}
 	return
 }
 
-func (g *Graph) Backprop(upstreamGrads []float64) {
-	visited := Set[*Node]{}
-	sorted := Stack[*Node]{}
-
-	for _, out := range g.Outputs {
-		if !visited[out] {
-			g.TopologicalSort(out, visited, &sorted, true)
+func (g *Graph) Backprop(upstreamGrads []float64) (err error) {
+	if len(upstreamGrads) != len(g.Outputs) {
+		err = fmt.Errorf("error list of upstream gradients must match list of outputs")
+		return
+	}
+	if g.Strict {
+		err = g.Validate()
+		if err != nil {
+			return
 		}
 	}
 
@@ -58,11 +123,11 @@ func (g *Graph) Backprop(upstreamGrads []float64) {
 		g.Outputs[i].Grad = upstreamGrads[i]
 	}
 
-	for {
-		n, empty := sorted.Pop()
-		if empty {
-			break
+	for _, n := range g.ReverseSchedule() {
+		err = n.ComputeGrad()
+		if err != nil {
+			return
 		}
-		n.ComputeGrad()
 	}
+	return
 }
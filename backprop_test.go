@@ -42,7 +42,7 @@ func TestBackProp1(t *testing.T) {
 // f(p,q,r,x,y,z,b) = px + qy + rz + b
 func TestBackProp2(t *testing.T) {
 
-	linear := NewLinear(3, 1, "l")
+	linear := NewLinearInit(3, 1, "l", rand.New(rand.NewSource(42)))
 	graph := linear.Graph
 
 	err := graph.Forward([]float64{4, -6, 7, -1, 5, 2, 1})
@@ -73,17 +73,17 @@ func TestBackProp2(t *testing.T) {
 
 // given points (0, 2) and (3, 0), find the equation of line
 func TestBackProp3(t *testing.T) {
-	linear := NewLinear(2, 1, "l")
-	optimizer := NewOptimizer(len(linear.Params), 1e-2, rand.New(rand.NewSource(42)))
+	linear := NewLinearInit(2, 1, "l", rand.New(rand.NewSource(42)))
+	optimizer := NewOptimizer(1e-2)
 	losses := []float64{}
 	for i := 0; i < 100; i++ {
-		err := linear.Forward([]float64{0, 2}, &optimizer)
+		err := linear.Forward([]float64{0, 2})
 		Panic(err)
 		loss1 := math.Pow(linear.Graph.Outputs[0].Val, 2)
 		linear.Graph.ZeroGrad()
 		linear.Backprop([]float64{2 * linear.Graph.Outputs[0].Val}, &optimizer)
 
-		err = linear.Forward([]float64{3, 0}, &optimizer)
+		err = linear.Forward([]float64{3, 0})
 		Panic(err)
 		loss2 := math.Pow(linear.Graph.Outputs[0].Val, 2)
 		linear.Graph.ZeroGrad()
@@ -91,7 +91,7 @@ func TestBackProp3(t *testing.T) {
 
 		Append(&losses, loss1+loss2)
 	}
-	p := optimizer.params
+	p := linear.Weights()
 	assert.True(t, IsNonIncreasing(losses))
 	assert.InEpsilon(t, -1./3., p[0]/p[2], 1e-3)
 	assert.InEpsilon(t, -1./2., p[1]/p[2], 1e-3)
@@ -257,14 +257,14 @@ func TestBackprop8(t *testing.T) {
 x - 3y = 3 passes through (0, -1) and (3, 0)
 */
 func TestBackProp9(t *testing.T) {
-	linear := NewLinear(2, 2, "l")
-	optimizer := NewOptimizer(len(linear.Params), 1e-2, rand.New(rand.NewSource(42)))
+	linear := NewLinearInit(2, 2, "l", rand.New(rand.NewSource(42)))
+	optimizer := NewOptimizer(1e-2)
 	losses1 := []float64{}
 	losses2 := []float64{}
 	for i := 0; i < 100; i++ {
 		localLoss := []float64{}
 		for _, point := range [][]float64{{0, 5}, {2.5, 0}} {
-			err := linear.Forward(point, &optimizer)
+			err := linear.Forward(point)
 			Panic(err)
 			Append(&localLoss, math.Pow(linear.Graph.Outputs[0].Val, 2))
 			linear.Graph.ZeroGrad()
@@ -274,7 +274,7 @@ func TestBackProp9(t *testing.T) {
 
 		localLoss = []float64{}
 		for _, point := range [][]float64{{0, -1.}, {3, 0}} {
-			err := linear.Forward(point, &optimizer)
+			err := linear.Forward(point)
 			Panic(err)
 			Append(&localLoss, math.Pow(linear.Graph.Outputs[1].Val, 2))
 			linear.Graph.ZeroGrad()
@@ -282,7 +282,7 @@ func TestBackProp9(t *testing.T) {
 		}
 		Append(&losses2, Sum(localLoss))
 	}
-	p := optimizer.params
+	p := linear.Weights()
 	assert.True(t, IsNonIncreasing(losses1))
 	assert.True(t, IsNonIncreasing(losses2))
 
@@ -362,24 +362,26 @@ func TestBackprop12(t *testing.T) {
 	err := s.Forward([]float64{1, 2, 3})
 	Panic(err)
 
-	expSum := math.Exp(1) + math.Exp(2) + math.Exp(3)
+	// the exponentials are taken after subtracting the largest input
+	e1, e2, e3 := math.Exp(1-3), math.Exp(2-3), math.Exp(0)
+	expSum := e1 + e2 + e3
 
 	assert.Equal(t, 1., s.Inputs[0].Val)
 	assert.Equal(t, 2., s.Inputs[1].Val)
 	assert.Equal(t, 3., s.Inputs[2].Val)
-	assert.InEpsilon(t, math.Exp(1), s.Intermediates[0].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(2), s.Intermediates[1].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(3), s.Intermediates[2].Val, 1e-6)
+	assert.InEpsilon(t, e1, s.Intermediates[0].Val, 1e-6)
+	assert.InEpsilon(t, e2, s.Intermediates[1].Val, 1e-6)
+	assert.InEpsilon(t, e3, s.Intermediates[2].Val, 1e-6)
 	assert.InEpsilon(t, expSum, s.Intermediates[3].Val, 1e-6)
 	assert.InEpsilon(t, 1./expSum, s.Intermediates[4].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(1)/expSum, s.Intermediates[5].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(2)/expSum, s.Intermediates[6].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(3)/expSum, s.Intermediates[7].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(1)/expSum, s.Outputs[0].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(2)/expSum, s.Outputs[1].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(3)/expSum, s.Outputs[2].Val, 1e-6)
-
-	reciprocalGrad := math.Exp(1) + 1.5*math.Exp(2) + 2*math.Exp(3)
+	assert.InEpsilon(t, e1/expSum, s.Intermediates[5].Val, 1e-6)
+	assert.InEpsilon(t, e2/expSum, s.Intermediates[6].Val, 1e-6)
+	assert.InEpsilon(t, e3/expSum, s.Intermediates[7].Val, 1e-6)
+	assert.InEpsilon(t, e1/expSum, s.Outputs[0].Val, 1e-6)
+	assert.InEpsilon(t, e2/expSum, s.Outputs[1].Val, 1e-6)
+	assert.InEpsilon(t, e3/expSum, s.Outputs[2].Val, 1e-6)
+
+	reciprocalGrad := e1 + 1.5*e2 + 2*e3
 	addGrad := reciprocalGrad * (-1) / (expSum * expSum)
 
 	s.Backprop([]float64{1, 1.5, 2})
@@ -394,9 +396,9 @@ func TestBackprop12(t *testing.T) {
 	assert.InEpsilon(t, addGrad+1./expSum, s.Intermediates[0].Grad, 1e-6)
 	assert.InEpsilon(t, addGrad+1.5/expSum, s.Intermediates[1].Grad, 1e-6)
 	assert.InEpsilon(t, addGrad+2./expSum, s.Intermediates[2].Grad, 1e-6)
-	assert.InEpsilon(t, math.Exp(1)*(addGrad+1./expSum), s.Inputs[0].Grad, 1e-6)
-	assert.InEpsilon(t, math.Exp(2)*(addGrad+1.5/expSum), s.Inputs[1].Grad, 1e-6)
-	assert.InEpsilon(t, math.Exp(3)*(addGrad+2./expSum), s.Inputs[2].Grad, 1e-6)
+	assert.InEpsilon(t, e1*(addGrad+1./expSum), s.Inputs[0].Grad, 1e-6)
+	assert.InEpsilon(t, e2*(addGrad+1.5/expSum), s.Inputs[1].Grad, 1e-6)
+	assert.InEpsilon(t, e3*(addGrad+2./expSum), s.Inputs[2].Grad, 1e-6)
 }
 
 func TestBackprop13(t *testing.T) {
@@ -405,34 +407,321 @@ func TestBackprop13(t *testing.T) {
 	s := Merge([]Graph{s1, s2})
 	err := s.Forward([]float64{1, 2, 3})
 	Panic(err)
-	expSum := math.Exp(1) + math.Exp(2) + math.Exp(3)
-	expSum2 := math.Exp(math.Exp(1)/expSum) + math.Exp(math.Exp(2)/expSum) + math.Exp(math.Exp(3)/expSum)
+	// the exponentials are taken after subtracting the largest input, which
+	// is 3 for s1 and p3 for s2
+	e1, e2, e3 := math.Exp(1-3), math.Exp(2-3), math.Exp(0)
+	expSum := e1 + e2 + e3
+	p1, p2, p3 := e1/expSum, e2/expSum, e3/expSum
+	f1, f2, f3 := math.Exp(p1-p3), math.Exp(p2-p3), math.Exp(0)
+	expSum2 := f1 + f2 + f3
 	assert.Equal(t, 1., s.Inputs[0].Val)
 	assert.Equal(t, 2., s.Inputs[1].Val)
 	assert.Equal(t, 3., s.Inputs[2].Val)
-	assert.InEpsilon(t, math.Exp(1), s.Intermediates[0].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(2), s.Intermediates[1].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(3), s.Intermediates[2].Val, 1e-6)
+	assert.InEpsilon(t, e1, s.Intermediates[0].Val, 1e-6)
+	assert.InEpsilon(t, e2, s.Intermediates[1].Val, 1e-6)
+	assert.InEpsilon(t, e3, s.Intermediates[2].Val, 1e-6)
 	assert.InEpsilon(t, expSum, s.Intermediates[3].Val, 1e-6)
 	assert.InEpsilon(t, 1./expSum, s.Intermediates[4].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(1)/expSum, s.Intermediates[5].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(2)/expSum, s.Intermediates[6].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(3)/expSum, s.Intermediates[7].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(1)/expSum, s.Intermediates[8].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(2)/expSum, s.Intermediates[9].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(3)/expSum, s.Intermediates[10].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(1)/expSum, s.Intermediates[11].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(2)/expSum, s.Intermediates[12].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(3)/expSum, s.Intermediates[13].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(math.Exp(1)/expSum), s.Intermediates[14].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(math.Exp(2)/expSum), s.Intermediates[15].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(math.Exp(3)/expSum), s.Intermediates[16].Val, 1e-6)
-	assert.InEpsilon(t, expSum2, s.Intermediates[17].Val, 1e-6)
-	assert.InEpsilon(t, 1./expSum2, s.Intermediates[18].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(math.Exp(1)/expSum)/expSum2, s.Intermediates[19].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(math.Exp(2)/expSum)/expSum2, s.Intermediates[20].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(math.Exp(3)/expSum)/expSum2, s.Intermediates[21].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(math.Exp(1)/expSum)/expSum2, s.Outputs[0].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(math.Exp(2)/expSum)/expSum2, s.Outputs[1].Val, 1e-6)
-	assert.InEpsilon(t, math.Exp(math.Exp(3)/expSum)/expSum2, s.Outputs[2].Val, 1e-6)
+	assert.InEpsilon(t, e1/expSum, s.Intermediates[5].Val, 1e-6)
+	assert.InEpsilon(t, e2/expSum, s.Intermediates[6].Val, 1e-6)
+	assert.InEpsilon(t, e3/expSum, s.Intermediates[7].Val, 1e-6)
+	assert.InEpsilon(t, e1/expSum, s.Intermediates[14].Val, 1e-6)
+	assert.InEpsilon(t, e2/expSum, s.Intermediates[15].Val, 1e-6)
+	assert.InEpsilon(t, e3/expSum, s.Intermediates[16].Val, 1e-6)
+	assert.InEpsilon(t, e1/expSum, s.Intermediates[17].Val, 1e-6)
+	assert.InEpsilon(t, e2/expSum, s.Intermediates[18].Val, 1e-6)
+	assert.InEpsilon(t, e3/expSum, s.Intermediates[19].Val, 1e-6)
+	assert.InEpsilon(t, f1, s.Intermediates[20].Val, 1e-6)
+	assert.InEpsilon(t, f2, s.Intermediates[21].Val, 1e-6)
+	assert.InEpsilon(t, f3, s.Intermediates[22].Val, 1e-6)
+	assert.InEpsilon(t, expSum2, s.Intermediates[23].Val, 1e-6)
+	assert.InEpsilon(t, 1./expSum2, s.Intermediates[24].Val, 1e-6)
+	assert.InEpsilon(t, f1/expSum2, s.Intermediates[25].Val, 1e-6)
+	assert.InEpsilon(t, f2/expSum2, s.Intermediates[26].Val, 1e-6)
+	assert.InEpsilon(t, f3/expSum2, s.Intermediates[27].Val, 1e-6)
+	assert.InEpsilon(t, f1/expSum2, s.Outputs[0].Val, 1e-6)
+	assert.InEpsilon(t, f2/expSum2, s.Outputs[1].Val, 1e-6)
+	assert.InEpsilon(t, f3/expSum2, s.Outputs[2].Val, 1e-6)
+}
+
+// f(x, y, z) = x * y * z at x = 0
+func TestBackProp14(t *testing.T) {
+	var a Node
+	x := InputSymbol("x", [](*Node){&a})
+	y := InputSymbol("y", [](*Node){&a})
+	z := InputSymbol("z", [](*Node){&a})
+	f := OutputSymbol("f", &a)
+	a = MultiplyNode("a", [](*Node){&f}, [](*Node){&x, &y, &z})
+
+	graph := NewGraph([](*Node){&x, &y, &z}, [](*Node){&f}, [](*Node){&a})
+
+	err := graph.Forward([]float64{0, 3, 4})
+	Panic(err)
+	assert.Equal(t, 0.0, a.Val)
+
+	graph.Backprop([]float64{1})
+	assert.Equal(t, 12.0, x.Grad)
+	assert.Equal(t, 0.0, y.Grad)
+	assert.Equal(t, 0.0, z.Grad)
+
+	graph.ZeroGrad()
+
+	err = graph.Forward([]float64{0, 0, 4})
+	Panic(err)
+	graph.Backprop([]float64{1})
+	assert.Equal(t, 0.0, x.Grad)
+	assert.Equal(t, 0.0, y.Grad)
+	assert.Equal(t, 0.0, z.Grad)
+}
+
+// f(x) = x * x * x at x = 0
+func TestBackProp15(t *testing.T) {
+	var a Node
+	x := InputSymbol("x", [](*Node){&a})
+	f := OutputSymbol("f", &a)
+	a = MultiplyNode("a", [](*Node){&f}, [](*Node){&x, &x, &x})
+
+	graph := NewGraph([](*Node){&x}, [](*Node){&f}, [](*Node){&a})
+
+	err := graph.Forward([]float64{0})
+	Panic(err)
+	graph.Backprop([]float64{1})
+	assert.Equal(t, 0.0, x.Grad)
+
+	graph.ZeroGrad()
+
+	err = graph.Forward([]float64{-1})
+	Panic(err)
+	graph.Backprop([]float64{1})
+	assert.Equal(t, 3.0, x.Grad)
+}
+
+// f(x) = log(x), g(x) = sqrt(x), h(x) = x^3
+func TestBackProp16(t *testing.T) {
+	var a, b, c Node
+	x := InputSymbol("x", [](*Node){&a, &b, &c})
+	f := OutputSymbol("f", &a)
+	g := OutputSymbol("g", &b)
+	h := OutputSymbol("h", &c)
+	a = LogNode("a", [](*Node){&f}, &x)
+	b = SqrtNode("b", [](*Node){&g}, &x)
+	c = PowNode("c", [](*Node){&h}, &x, 3)
+
+	graph := NewGraph([](*Node){&x}, [](*Node){&f, &g, &h}, [](*Node){&a, &b, &c})
+
+	err := graph.Forward([]float64{4})
+	Panic(err)
+	assert.InDelta(t, math.Log(4), f.Val, 1e-9)
+	assert.Equal(t, 2.0, g.Val)
+	assert.Equal(t, 64.0, h.Val)
+
+	graph.Backprop([]float64{1, 0, 0})
+	assert.Equal(t, 0.25, x.Grad)
+
+	graph.ZeroGrad()
+	graph.Backprop([]float64{0, 1, 0})
+	assert.Equal(t, 0.25, x.Grad)
+
+	graph.ZeroGrad()
+	graph.Backprop([]float64{0, 0, 1})
+	assert.Equal(t, 48.0, x.Grad)
+
+	err = graph.Forward([]float64{0})
+	assert.ErrorIs(t, err, ErrDomain)
+
+	err = graph.Forward([]float64{-1})
+	assert.ErrorIs(t, err, ErrDomain)
+}
+
+// f(x, y) = x^y
+func TestBackProp17(t *testing.T) {
+	var a Node
+	x := InputSymbol("x", [](*Node){&a})
+	y := InputSymbol("y", [](*Node){&a})
+	f := OutputSymbol("f", &a)
+	a = PowVarNode("a", [](*Node){&f}, &x, &y)
+
+	graph := NewGraph([](*Node){&x, &y}, [](*Node){&f}, [](*Node){&a})
+
+	err := graph.Forward([]float64{2, 3})
+	Panic(err)
+	assert.Equal(t, 8.0, f.Val)
+
+	graph.Backprop([]float64{1})
+	assert.Equal(t, 12.0, x.Grad)
+	assert.InDelta(t, 8*math.Log(2), y.Grad, 1e-9)
+
+	err = graph.Forward([]float64{-2, 3})
+	Panic(err)
+	assert.Equal(t, -8.0, f.Val)
+
+	err = graph.Forward([]float64{-2, 0.5})
+	assert.ErrorIs(t, err, ErrDomain)
+
+	err = graph.Forward([]float64{0, -1})
+	assert.ErrorIs(t, err, ErrDomain)
+
+	err = graph.Forward([]float64{0, 0})
+	Panic(err)
+	assert.Equal(t, 1.0, f.Val)
+}
+
+// tanh, sigmoid, softplus, gelu, elu and leaky relu of the same input
+func TestBackProp18(t *testing.T) {
+	var a, b, c, d, e, g Node
+	x := InputSymbol("x", [](*Node){&a, &b, &c, &d, &e, &g})
+	f1 := OutputSymbol("f1", &a)
+	f2 := OutputSymbol("f2", &b)
+	f3 := OutputSymbol("f3", &c)
+	f4 := OutputSymbol("f4", &d)
+	f5 := OutputSymbol("f5", &e)
+	f6 := OutputSymbol("f6", &g)
+	a = TanhNode("a", [](*Node){&f1}, &x)
+	b = SigmoidNode("b", [](*Node){&f2}, &x)
+	c = SoftplusNode("c", [](*Node){&f3}, &x)
+	d = GeluNode("d", [](*Node){&f4}, &x)
+	e = EluNode("e", [](*Node){&f5}, &x, 0.5)
+	g = LeakyReluNode("g", [](*Node){&f6}, &x, 0.1)
+
+	outputs := [](*Node){&f1, &f2, &f3, &f4, &f5, &f6}
+	graph := NewGraph([](*Node){&x}, outputs, [](*Node){&a, &b, &c, &d, &e, &g})
+
+	phi := func(x float64) float64 { return 0.5 * (1 + math.Erf(x/math.Sqrt2)) }
+	sig := func(x float64) float64 { return 1 / (1 + math.Exp(-x)) }
+
+	for _, v := range []float64{1.5, -2} {
+		err := graph.Forward([]float64{v})
+		Panic(err)
+		assert.InDelta(t, math.Tanh(v), f1.Val, 1e-9)
+		assert.InDelta(t, sig(v), f2.Val, 1e-9)
+		assert.InDelta(t, math.Log(1+math.Exp(v)), f3.Val, 1e-9)
+		assert.InDelta(t, v*phi(v), f4.Val, 1e-9)
+		if v > 0 {
+			assert.Equal(t, v, f5.Val)
+			assert.Equal(t, v, f6.Val)
+		} else {
+			assert.InDelta(t, 0.5*(math.Exp(v)-1), f5.Val, 1e-9)
+			assert.InDelta(t, 0.1*v, f6.Val, 1e-9)
+		}
+
+		wants := []float64{
+			1 - math.Pow(math.Tanh(v), 2),
+			sig(v) * (1 - sig(v)),
+			sig(v),
+			phi(v) + v*math.Exp(-v*v/2)/math.Sqrt(2*math.Pi),
+			1,
+			1,
+		}
+		if v <= 0 {
+			wants[4] = 0.5 * math.Exp(v)
+			wants[5] = 0.1
+		}
+		for i, want := range wants {
+			upstream := make([]float64, len(outputs))
+			upstream[i] = 1
+			graph.ZeroGrad()
+			graph.Backprop(upstream)
+			assert.InDelta(t, want, x.Grad, 1e-9, outputs[i].Label)
+		}
+	}
+
+	// saturating inputs must not produce NaN or Inf
+	for _, v := range []float64{1000, -1000} {
+		err := graph.Forward([]float64{v})
+		Panic(err)
+		for _, out := range outputs {
+			assert.False(t, math.IsNaN(out.Val) || math.IsInf(out.Val, 0), out.Label)
+		}
+		assert.Equal(t, math.Max(v, 0), f3.Val)
+		graph.ZeroGrad()
+		graph.Backprop([]float64{1, 1, 1, 1, 1, 1})
+		assert.False(t, math.IsNaN(x.Grad) || math.IsInf(x.Grad, 0))
+	}
+}
+
+// f(x) = exp(x * x), built by feeding one graph into another with MergeTwo
+func TestBackProp19(t *testing.T) {
+	var a, b Node
+	x := InputSymbol("x", [](*Node){&a})
+	u := OutputSymbol("u", &a)
+	a = MultiplyNode("a", [](*Node){&u}, [](*Node){&x, &x})
+	v := InputSymbol("v", [](*Node){&b})
+	f := OutputSymbol("f", &b)
+	b = ExpNode("b", [](*Node){&f}, &v)
+
+	graph := MergeTwo(
+		NewGraph([](*Node){&x}, [](*Node){&u}, [](*Node){&a}),
+		NewGraph([](*Node){&v}, [](*Node){&f}, [](*Node){&b}),
+	)
+
+	err := graph.Forward([]float64{1.5})
+	Panic(err)
+	assert.InEpsilon(t, math.Exp(2.25), f.Val, 1e-9)
+
+	// the gradient crosses from v, now fed by u, back into the first graph
+	Panic(graph.Backprop([]float64{1}))
+	assert.InEpsilon(t, math.Exp(2.25), v.Grad, 1e-9)
+	assert.InEpsilon(t, math.Exp(2.25), u.Grad, 1e-9)
+	assert.InEpsilon(t, 3*math.Exp(2.25), x.Grad, 1e-9)
+}
+
+func TestSchedule1(t *testing.T) {
+	s1 := SoftMax(2, "s1")
+	schedule := s1.Schedule()
+	// inputs, max, the constant -1, its product with the max, shifts, exps,
+	// add, reciprocal, multiplies and outputs
+	assert.Len(t, schedule, 15)
+	assert.Same(t, &schedule[0], &s1.Schedule()[0])
+	assert.Len(t, s1.ReverseSchedule(), 15)
+
+	err := s1.Forward([]float64{1, 2})
+	Panic(err)
+	assert.Same(t, &schedule[0], &s1.Schedule()[0])
+
+	position := map[*Node]int{}
+	for i, n := range schedule {
+		position[n] = i
+	}
+	for _, n := range schedule {
+		for _, out := range n.Outputs {
+			assert.Less(t, position[n], position[out])
+		}
+	}
+
+	s := MergeTwo(s1, SoftMax(2, "s2"))
+	assert.Len(t, s.Schedule(), 30)
+	assert.Len(t, s.ReverseSchedule(), 30)
+
+	s.Invalidate()
+	assert.Len(t, s.Schedule(), 30)
+}
+
+// a copy of a graph whose lists are extended does not reuse the schedules
+// cached by the original
+func TestSchedule2(t *testing.T) {
+	b := NewBuilder("f")
+	x := b.Input("x")
+	square := b.Mul(x, x)
+	g := b.Build(square)
+	assert.Len(t, g.Schedule(), 3)
+	assert.Len(t, g.ReverseSchedule(), 3)
+
+	h := g
+	e := &Node{Label: "e", Op: Exp, Inputs: [](*Node){square}}
+	out := OutputSymbol("f-output-1", e)
+	e.Outputs = [](*Node){&out}
+	Append(&square.Outputs, e)
+	h.Intermediates = append(h.Intermediates, e)
+	h.Outputs = append(h.Outputs, &out)
+
+	assert.Len(t, h.Schedule(), 5)
+	assert.Len(t, h.ReverseSchedule(), 5)
+	Panic(h.Forward([]float64{1.5}))
+	assert.InEpsilon(t, math.Exp(2.25), out.Val, 1e-12)
+
+	// g keeps its own lists and schedules, though they miss the new edge
+	// until it is invalidated
+	assert.Len(t, g.Schedule(), 3)
+	g.Invalidate()
+	assert.Len(t, g.Schedule(), 5)
 }
@@ -0,0 +1,152 @@
+package nngo
+
+import "fmt"
+
+// Builder records nodes as they are created and wires both directions of
+// every edge, so a graph can be written as nested expressions:
+//
+//	b := NewBuilder("f")
+//	x, y, w := b.Input("x"), b.Input("y"), b.Input("w")
+//	graph := b.Build(b.Mul(b.Add(x, y), w))
+type Builder struct {
+	label         string
+	inputs        [](*Node)
+	intermediates [](*Node)
+}
+
+func NewBuilder(label string) *Builder {
+	return &Builder{label: label}
+}
+
+// Input adds a graph input. Inputs are set by Graph.Forward in the order they
+// were created.
+func (b *Builder) Input(label string) *Node {
+	node := &Node{Label: label}
+	Append(&b.inputs, node)
+	return node
+}
+
+// Const adds a node with a fixed value that is not set by Graph.Forward.
+func (b *Builder) Const(val float64) *Node {
+	node := &Node{
+		Label: fmt.Sprintf("%s-const-%d", b.label, len(b.intermediates)),
+		Val:   val,
+	}
+	Append(&b.intermediates, node)
+	return node
+}
+
+// Op adds a node applying op to inputs, which is how registered custom
+// operators are used with a Builder.
+func (b *Builder) Op(op Op, param float64, inputs ...*Node) *Node {
+	node := &Node{
+		Label:  fmt.Sprintf("%s-%s-%d", b.label, op, len(b.intermediates)),
+		Op:     op,
+		Inputs: inputs,
+		Param:  param,
+	}
+	for _, inp := range inputs {
+		// repeated inputs like x*x are only linked back once
+		if !contains(inp.Outputs, node) {
+			Append(&inp.Outputs, node)
+		}
+	}
+	Append(&b.intermediates, node)
+	return node
+}
+
+func (b *Builder) Add(inputs ...*Node) *Node {
+	return b.Op(Add, 0, inputs...)
+}
+
+func (b *Builder) Mul(inputs ...*Node) *Node {
+	return b.Op(Multiply, 0, inputs...)
+}
+
+func (b *Builder) Dot(xs, ys [](*Node)) *Node {
+	inputs := append([](*Node){}, xs...)
+	Append(&inputs, ys...)
+	return b.Op(Dot, 0, inputs...)
+}
+
+func (b *Builder) Relu(x *Node) *Node {
+	return b.Op(Relu, 0, x)
+}
+
+func (b *Builder) Exp(x *Node) *Node {
+	return b.Op(Exp, 0, x)
+}
+
+func (b *Builder) Reciprocal(x *Node) *Node {
+	return b.Op(Reciprocal, 0, x)
+}
+
+func (b *Builder) Log(x *Node) *Node {
+	return b.Op(Log, 0, x)
+}
+
+func (b *Builder) Pow(x *Node, exponent float64) *Node {
+	return b.Op(Pow, exponent, x)
+}
+
+func (b *Builder) PowVar(base, exponent *Node) *Node {
+	return b.Op(Pow, 0, base, exponent)
+}
+
+func (b *Builder) Sqrt(x *Node) *Node {
+	return b.Op(Sqrt, 0, x)
+}
+
+func (b *Builder) Tanh(x *Node) *Node {
+	return b.Op(Tanh, 0, x)
+}
+
+func (b *Builder) Sigmoid(x *Node) *Node {
+	return b.Op(Sigmoid, 0, x)
+}
+
+func (b *Builder) Softplus(x *Node) *Node {
+	return b.Op(Softplus, 0, x)
+}
+
+func (b *Builder) Gelu(x *Node) *Node {
+	return b.Op(Gelu, 0, x)
+}
+
+func (b *Builder) Elu(x *Node, alpha float64) *Node {
+	return b.Op(Elu, alpha, x)
+}
+
+func (b *Builder) LeakyRelu(x *Node, slope float64) *Node {
+	return b.Op(LeakyRelu, slope, x)
+}
+
+func (b *Builder) Abs(x *Node) *Node {
+	return b.Op(Abs, 0, x)
+}
+
+func (b *Builder) Huber(x *Node, delta float64) *Node {
+	return b.Op(Huber, delta, x)
+}
+
+func (b *Builder) XLogY(x, y *Node) *Node {
+	return b.Op(XLogY, 0, x, y)
+}
+
+func (b *Builder) Max(inputs ...*Node) *Node {
+	return b.Op(Maximum, 0, inputs...)
+}
+
+// Build attaches an output symbol to each of outputs and returns the graph
+// made of everything created by the builder.
+func (b *Builder) Build(outputs ...*Node) Graph {
+	outs := make([](*Node), len(outputs))
+	for i, out := range outputs {
+		outs[i] = &Node{
+			Label:  fmt.Sprintf("%s-output-%d", b.label, i),
+			Inputs: [](*Node){out},
+		}
+		Append(&out.Outputs, outs[i])
+	}
+	return NewGraph(b.inputs, outs, b.intermediates)
+}
@@ -0,0 +1,84 @@
+package nngo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// f(x,y,z) = (x+y)*z, as in TestBackProp1
+func TestBuilder1(t *testing.T) {
+	b := NewBuilder("f")
+	x, y, z := b.Input("x"), b.Input("y"), b.Input("z")
+	a := b.Add(x, y)
+	graph := b.Build(b.Mul(a, z))
+
+	assert.Equal(t, [](*Node){a}, x.Outputs)
+	assert.Equal(t, [](*Node){x, y}, a.Inputs)
+	assert.Len(t, graph.Intermediates, 2)
+
+	err := graph.Forward([]float64{-2, 5, -4})
+	Panic(err)
+	assert.Equal(t, 3.0, a.Val)
+	assert.Equal(t, -12.0, graph.Outputs[0].Val)
+
+	graph.Backprop([]float64{1})
+	assert.Equal(t, -4.0, x.Grad)
+	assert.Equal(t, -4.0, y.Grad)
+	assert.Equal(t, 3.0, z.Grad)
+}
+
+// T([x, y]) = [x+y, x*y], f = sum(T(T([x, y]))), as in TestBackProp4
+func TestBuilder2(t *testing.T) {
+	b := NewBuilder("f")
+	x, y := b.Input("x"), b.Input("y")
+	T := func(u, v *Node) (*Node, *Node) {
+		return b.Add(u, v), b.Mul(u, v)
+	}
+	c, d := T(T(x, y))
+	graph := b.Build(b.Add(c, d))
+
+	err := graph.Forward([]float64{2, 3})
+	Panic(err)
+	assert.Equal(t, 41.0, graph.Outputs[0].Val)
+
+	graph.Backprop([]float64{1})
+	assert.Equal(t, 25.0, x.Grad)
+	assert.Equal(t, 19.0, y.Grad)
+}
+
+// f(x) = x*x*x + 2, with a constant and a repeated input
+func TestBuilder3(t *testing.T) {
+	b := NewBuilder("f")
+	x := b.Input("x")
+	cube := b.Mul(x, x, x)
+	graph := b.Build(b.Add(cube, b.Const(2)), cube)
+
+	assert.Equal(t, [](*Node){cube}, x.Outputs)
+	assert.Len(t, cube.Outputs, 2)
+
+	err := graph.Forward([]float64{2})
+	Panic(err)
+	assert.Equal(t, 10.0, graph.Outputs[0].Val)
+	assert.Equal(t, 8.0, graph.Outputs[1].Val)
+
+	graph.Backprop([]float64{1, 1})
+	assert.Equal(t, 24.0, x.Grad)
+}
+
+// f(x) = x * exp(0), with a subexpression made only of constants
+func TestBuilder4(t *testing.T) {
+	b := NewBuilder("f")
+	x := b.Input("x")
+	one := b.Exp(b.Const(0))
+	graph := b.Build(b.Mul(x, one))
+	assert.NoError(t, graph.Validate())
+
+	err := graph.Forward([]float64{3})
+	Panic(err)
+	assert.Equal(t, 1.0, one.Val)
+	assert.Equal(t, 3.0, graph.Outputs[0].Val)
+
+	graph.Backprop([]float64{1})
+	assert.Equal(t, 1.0, x.Grad)
+}
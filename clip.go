@@ -0,0 +1,97 @@
+package nngo
+
+import (
+	"fmt"
+	"math"
+)
+
+// GradientClipping limits gradients before they reach the optimizer. Each
+// limit is only applied when positive. Groups are clipped on their own first,
+// then the limits of the whole gradient apply.
+type GradientClipping struct {
+	MaxValue float64
+	MaxNorm  float64
+	Groups   []ParamGroup
+}
+
+// ParamGroup selects parameters by the Name of their Parameter, see
+// Module.Parameters, and limits their gradients like GradientClipping does.
+type ParamGroup struct {
+	Names    []string
+	MaxValue float64
+	MaxNorm  float64
+}
+
+// Apply clips the gradients of the parameters of m in place. A group naming
+// a parameter m does not have returns an error before any gradient changes.
+func (c *GradientClipping) Apply(m *Module) (err error) {
+	byName := map[string]Parameter{}
+	for _, p := range m.Parameters() {
+		byName[p.Name] = p
+	}
+	groups := make([][](*Node), len(c.Groups))
+	for i, group := range c.Groups {
+		for _, name := range group.Names {
+			p, ok := byName[name]
+			if !ok {
+				err = fmt.Errorf("error module has no parameter %s", name)
+				return
+			}
+			Append(&groups[i], p.Nodes...)
+		}
+	}
+
+	for i, group := range c.Groups {
+		clipNodes(groups[i], group.MaxValue, group.MaxNorm)
+	}
+	clipNodes(m.Params, c.MaxValue, c.MaxNorm)
+	return
+}
+
+func clipNodes(nodes [](*Node), maxValue, maxNorm float64) {
+	grads := nodeGrads(nodes)
+	clip(grads, maxValue, maxNorm)
+	for i, n := range nodes {
+		n.Grad = grads[i]
+	}
+}
+
+func nodeGrads(nodes [](*Node)) []float64 {
+	return Map(nodes, func(n *Node) float64 {
+		return n.Grad
+	})
+}
+
+func clip(grads []float64, maxValue, maxNorm float64) {
+	if maxValue > 0 {
+		ClipByValue(grads, maxValue)
+	}
+	if maxNorm > 0 {
+		ClipByGlobalNorm(grads, maxNorm)
+	}
+}
+
+// GlobalNorm is the euclidean norm of all gradients taken together.
+func GlobalNorm(grads []float64) float64 {
+	return math.Sqrt(DotProduct(grads, grads))
+}
+
+// ClipByValue limits every gradient to [-limit, limit].
+func ClipByValue(grads []float64, limit float64) {
+	for i := range grads {
+		grads[i] = math.Max(-limit, math.Min(limit, grads[i]))
+	}
+}
+
+// ClipByGlobalNorm scales grads down so their global norm is at most maxNorm
+// and returns the norm before scaling.
+func ClipByGlobalNorm(grads []float64, maxNorm float64) (norm float64) {
+	norm = GlobalNorm(grads)
+	if norm > maxNorm {
+		scale := maxNorm / norm
+		for i := range grads {
+			grads[i] *= scale
+		}
+	}
+	return
+}
@@ -0,0 +1,65 @@
+package nngo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClip1(t *testing.T) {
+	grads := []float64{3, -4, 0.5}
+	ClipByValue(grads, 1)
+	assert.Equal(t, []float64{1, -1, 0.5}, grads)
+
+	grads = []float64{3, -4}
+	assert.Equal(t, 5.0, ClipByGlobalNorm(grads, 1))
+	assert.InDeltaSlice(t, []float64{0.6, -0.8}, grads, 1e-12)
+
+	grads = []float64{0.3, -0.4}
+	assert.Equal(t, 0.5, ClipByGlobalNorm(grads, 1))
+	assert.Equal(t, []float64{0.3, -0.4}, grads)
+}
+
+func TestClip2(t *testing.T) {
+	linear := NewLinearInit(2, 1, "l", rand.New(rand.NewSource(42)))
+	clipping := GradientClipping{
+		MaxNorm: 2,
+		Groups: []ParamGroup{
+			{Names: []string{"l-weight"}, MaxNorm: 1},
+			{Names: []string{"l-bias"}, MaxValue: 2},
+		},
+	}
+	weights, bias := linear.Parameters()[0].Nodes, linear.Parameters()[1].Nodes[0]
+	weights[0].Grad, weights[1].Grad, bias.Grad = 3, 4, -5
+	Panic(clipping.Apply(&linear))
+	// the groups give [0.6, 0.8, -2], whose norm is then scaled to 2
+	want := []float64{0.6, 0.8, -2}
+	scale := 2 / GlobalNorm(want)
+	for i := range want {
+		want[i] *= scale
+	}
+	assert.InDeltaSlice(t, want, []float64{weights[0].Grad, weights[1].Grad, bias.Grad}, 1e-12)
+
+	clipping.Groups[1].Names = []string{"l-biases"}
+	bias.Grad = -5
+	assert.Error(t, clipping.Apply(&linear))
+	assert.Equal(t, -5.0, bias.Grad)
+}
+
+// f(x, w) = exp(w * x) explodes for large x
+func TestClip3(t *testing.T) {
+	b := NewBuilder("f")
+	x, w := b.Input("x"), b.Input("w")
+	m := Module{Graph: b.Build(b.Exp(b.Mul(w, x))), Params: [](*Node){w}}
+	optimizer := NewOptimizer(0.1)
+	w.Val = 1
+
+	Panic(m.Forward([]float64{50}))
+	m.Graph.ZeroGrad()
+	norm, err := m.BackpropClipped([]float64{1}, &optimizer, &GradientClipping{MaxNorm: 1})
+	Panic(err)
+	assert.InEpsilon(t, 50*math.Exp(50), norm, 1e-9)
+	assert.InDelta(t, 0.9, w.Val, 1e-12)
+}
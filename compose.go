@@ -0,0 +1,118 @@
+package nngo
+
+import "fmt"
+
+// Wire connects the output of one graph labelled From to the input of another
+// labelled To.
+type Wire struct {
+	From string
+	To   string
+}
+
+// Compose feeds outputs of x into inputs of y, matching them by Label as
+// listed in wires. One output may feed several inputs, but each input is fed
+// at most once. The inputs of the result are those of x followed by the inputs
+// of y left open, and its outputs are the outputs of x that feed nothing
+// followed by those of y. Unlike MergeTwo, nothing is wired by position and
+// wires naming a missing or ambiguous port return an error before either
+// graph is changed.
+func Compose(x, y Graph, wires ...Wire) (g Graph, err error) {
+	froms := make([](*Node), len(wires))
+	tos := make([](*Node), len(wires))
+	fed := Set[*Node]{}
+	for i, w := range wires {
+		froms[i], err = findPort(x.Outputs, w.From, "output")
+		if err != nil {
+			return
+		}
+		tos[i], err = findPort(y.Inputs, w.To, "input")
+		if err != nil {
+			return
+		}
+		if fed[tos[i]] {
+			err = fmt.Errorf("error input %s is fed more than once", w.To)
+			return
+		}
+		fed[tos[i]] = true
+	}
+
+	g.Subgraphs = append(x.subgraphs(), y.subgraphs()...)
+	used := Set[*Node]{}
+	for i := range wires {
+		Append(&froms[i].Outputs, tos[i])
+		Append(&tos[i].Inputs, froms[i])
+		used[froms[i]] = true
+	}
+
+	g.Inputs = append([](*Node){}, x.Inputs...)
+	for _, n := range y.Inputs {
+		if !fed[n] {
+			Append(&g.Inputs, n)
+		}
+	}
+	g.Intermediates = append([](*Node){}, x.Intermediates...)
+	for _, n := range x.Outputs {
+		if used[n] {
+			Append(&g.Intermediates, n)
+		} else {
+			Append(&g.Outputs, n)
+		}
+	}
+	for _, n := range y.Inputs {
+		if fed[n] {
+			Append(&g.Intermediates, n)
+		}
+	}
+	Append(&g.Intermediates, y.Intermediates...)
+	Append(&g.Outputs, y.Outputs...)
+	g.Strict = x.Strict || y.Strict
+	return
+}
+
+// Parallel places graphs side by side, concatenating their inputs and their
+// outputs. Labels of inputs and of outputs must stay unique so that they can
+// still be wired by Compose.
+func Parallel(graphs ...Graph) (g Graph, err error) {
+	inputs, outputs := Set[string]{}, Set[string]{}
+	for _, h := range graphs {
+		for _, n := range h.Inputs {
+			if inputs[n.Label] {
+				err = fmt.Errorf("error more than one input is labelled %s", n.Label)
+				return
+			}
+			inputs[n.Label] = true
+		}
+		for _, n := range h.Outputs {
+			if outputs[n.Label] {
+				err = fmt.Errorf("error more than one output is labelled %s", n.Label)
+				return
+			}
+			outputs[n.Label] = true
+		}
+	}
+	for _, h := range graphs {
+		Append(&g.Subgraphs, h.subgraphs()...)
+		Append(&g.Inputs, h.Inputs...)
+		Append(&g.Intermediates, h.Intermediates...)
+		Append(&g.Outputs, h.Outputs...)
+		g.Strict = g.Strict || h.Strict
+	}
+	return
+}
+
+func findPort(ports [](*Node), label, kind string) (port *Node, err error) {
+	for _, n := range ports {
+		if n.Label != label {
+			continue
+		}
+		if port != nil {
+			err = fmt.Errorf("error more than one %s is labelled %s", kind, label)
+			return
+		}
+		port = n
+	}
+	if port == nil {
+		err = fmt.Errorf("error no %s is labelled %s", kind, label)
+	}
+	return
+}
@@ -0,0 +1,87 @@
+package nngo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the outputs of one softmax feed the inputs of another in reverse order
+func TestCompose1(t *testing.T) {
+	g, err := Compose(SoftMax(2, "s1"), SoftMax(2, "s2"),
+		Wire{From: "s1-output-0", To: "s2-input-1"},
+		Wire{From: "s1-output-1", To: "s2-input-0"},
+	)
+	Panic(err)
+	assert.NoError(t, g.Validate())
+	assert.Len(t, g.Inputs, 2)
+	assert.Len(t, g.Outputs, 2)
+
+	Panic(g.Forward([]float64{1, 2}))
+	p0 := 1 / (1 + math.E)
+	q0 := 1 / (1 + math.Exp(p0-(1-p0)))
+	assert.InEpsilon(t, q0, g.Outputs[0].Val, 1e-9)
+	assert.InEpsilon(t, 1-q0, g.Outputs[1].Val, 1e-9)
+
+	g.ZeroGrad()
+	Panic(g.Backprop([]float64{1, 0}))
+	// dq0/dp0 = -2 q0 (1 - q0) and dp0/dx0 = p0 (1 - p0)
+	assert.InEpsilon(t, -2*q0*(1-q0)*p0*(1-p0), g.Inputs[0].Grad, 1e-9)
+	assert.InEpsilon(t, 2*q0*(1-q0)*p0*(1-p0), g.Inputs[1].Grad, 1e-9)
+}
+
+// f = x * x feeds one input of each of two softmaxes placed side by side,
+// whose other inputs are left open
+func TestCompose2(t *testing.T) {
+	b := NewBuilder("f")
+	x := b.Input("x")
+	f := b.Build(b.Mul(x, x))
+	side, err := Parallel(SoftMax(2, "p"), SoftMax(2, "q"))
+	Panic(err)
+
+	g, err := Compose(f, side,
+		Wire{From: "f-output-0", To: "p-input-0"},
+		Wire{From: "f-output-0", To: "q-input-1"},
+	)
+	Panic(err)
+	assert.NoError(t, g.Validate())
+	assert.Equal(t, []string{"x", "p-input-1", "q-input-0"}, Map(g.Inputs, func(n *Node) string {
+		return n.Label
+	}))
+	assert.Len(t, g.Outputs, 4)
+
+	Panic(g.Forward([]float64{1, 2, 3}))
+	p0 := 1 / (1 + math.Exp(1))
+	q1 := 1 / (1 + math.Exp(2))
+	assert.InEpsilon(t, p0, g.Outputs[0].Val, 1e-9)
+	assert.InEpsilon(t, q1, g.Outputs[3].Val, 1e-9)
+
+	g.ZeroGrad()
+	Panic(g.Backprop([]float64{1, 0, 0, 1}))
+	// both branches add up at x, each times df/dx = 2x
+	assert.InEpsilon(t, 2*(p0*(1-p0)+q1*(1-q1)), g.Inputs[0].Grad, 1e-9)
+}
+
+func TestCompose3(t *testing.T) {
+	s1, s2 := SoftMax(2, "s1"), SoftMax(2, "s2")
+	_, err := Compose(s1, s2, Wire{From: "s1-output-2", To: "s2-input-0"})
+	assert.ErrorContains(t, err, "no output is labelled s1-output-2")
+	_, err = Compose(s1, s2, Wire{From: "s1-output-0", To: "s1-input-0"})
+	assert.ErrorContains(t, err, "no input is labelled s1-input-0")
+	_, err = Compose(s1, s2,
+		Wire{From: "s1-output-0", To: "s2-input-0"},
+		Wire{From: "s1-output-1", To: "s2-input-0"},
+	)
+	assert.ErrorContains(t, err, "fed more than once")
+	// nothing was wired by the failed calls
+	assert.Empty(t, s2.Inputs[0].Inputs)
+	assert.Len(t, s1.Outputs[0].Outputs, 0)
+
+	_, err = Parallel(s1, SoftMax(2, "s1"))
+	assert.ErrorContains(t, err, "more than one input is labelled s1-input-0")
+	twice, err := Parallel(s1, s2)
+	Panic(err)
+	_, err = Compose(twice, SoftMax(1, "s3"), Wire{From: "s1-output-0", To: "s3-input-0"})
+	assert.NoError(t, err)
+}
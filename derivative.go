@@ -0,0 +1,320 @@
+package nngo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Derivative adds nodes to b computing the partial derivatives of a node with
+// the given inputs, output and Param, one per input. A nil partial is zero.
+// Unlike Operator.Backward, the partials are nodes themselves, so they can be
+// differentiated again.
+type Derivative func(b *Builder, inputs [](*Node), out *Node, param float64) [](*Node)
+
+var derivatives = map[Op]Derivative{}
+
+// Ops that only the derivative rules build, registered like the built-in ones
+// but not part of the API.
+const (
+	opHeaviside          Op = "heaviside"
+	opNormalCDF          Op = "normal-cdf"
+	opIsMax              Op = "is-max"
+	opPositiveReciprocal Op = "positive-reciprocal"
+)
+
+// RegisterDerivative lets GradGraph differentiate nodes of op, typically a
+// custom operator. Like RegisterOperator it is meant for init functions.
+func RegisterDerivative(op Op, d Derivative) (err error) {
+	if _, ok := derivatives[op]; ok {
+		err = fmt.Errorf("error derivative of %q is already registered", op)
+		return
+	}
+	derivatives[op] = d
+	return
+}
+
+// GradGraph returns the backward pass of g as a graph of its own, built from
+// ordinary nodes so it can be evaluated, composed and backpropagated like any
+// other graph. Its inputs are copies of the inputs of g followed by one
+// upstream gradient per output, labelled "<output>-upstream", and its outputs
+// are the gradients of the inputs of g.
+//
+// Backpropagating the result gives second derivatives, e.g. Hessian-vector
+// products. Nodes of g that do not depend on its inputs, like the unit node
+// of NewLinear, are copied as constants with their current values.
+func (g *Graph) GradGraph(label string) (d Graph, err error) {
+	for _, n := range g.Schedule() {
+		if n.Op == "" {
+			continue
+		}
+		if _, ok := derivatives[n.Op]; !ok {
+			err = fmt.Errorf("error op %q at node %s has no registered derivative", n.Op, n.Label)
+			return
+		}
+	}
+
+	b := NewBuilder(label)
+	copies := map[*Node]*Node{}
+	for _, n := range g.Inputs {
+		copies[n] = b.Input(n.Label)
+	}
+	copyOf := func(n *Node) *Node {
+		c, ok := copies[n]
+		if !ok {
+			c = b.Const(n.Val)
+			copies[n] = c
+		}
+		return c
+	}
+	// only nodes that depend on the inputs have gradients to pass on
+	variable := Set[*Node]{}
+	for _, n := range topologicalOrder(g.Inputs, neighbors(false)) {
+		variable[n] = true
+	}
+	for _, n := range g.Schedule() {
+		if _, ok := copies[n]; ok || len(n.Inputs) == 0 {
+			continue
+		}
+		inputs := Map(n.Inputs, copyOf)
+		if n.Op == "" {
+			copies[n] = inputs[0]
+		} else {
+			copies[n] = b.Op(n.Op, n.Param, inputs...)
+		}
+	}
+
+	// the contributions to the gradient of each node, added up once all of
+	// them are known
+	adjoints := map[*Node][](*Node){}
+	contribute := func(n, grad *Node) {
+		adjoints[n] = append(adjoints[n], grad)
+	}
+	for _, out := range g.Outputs {
+		contribute(out, b.Input(fmt.Sprintf("%s-upstream", out.Label)))
+	}
+	isInput := Set[*Node]{}
+	for _, n := range g.Inputs {
+		isInput[n] = true
+	}
+	for _, n := range g.ReverseSchedule() {
+		if !variable[n] || len(adjoints[n]) == 0 || isInput[n] {
+			continue
+		}
+		total := b.sum(adjoints[n])
+		if n.Op == "" {
+			contribute(n.Inputs[0], total)
+			continue
+		}
+		partials := derivatives[n.Op](b, Map(n.Inputs, copyOf), copies[n], n.Param)
+		for i, inp := range n.Inputs {
+			if !variable[inp] || partials[i] == nil {
+				continue
+			}
+			if b.isConst(partials[i], 1) {
+				contribute(inp, total)
+			} else {
+				contribute(inp, b.Mul(total, partials[i]))
+			}
+		}
+	}
+
+	grads := make([](*Node), len(g.Inputs))
+	for i, n := range g.Inputs {
+		if len(adjoints[n]) == 0 {
+			grads[i] = b.Const(0)
+		} else {
+			grads[i] = b.sum(adjoints[n])
+		}
+	}
+	d = b.Build(grads...)
+	return
+}
+
+func (b *Builder) sum(nodes [](*Node)) *Node {
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+	return b.Add(nodes...)
+}
+
+// product multiplies nodes, folding the constants of the builder among them
+// into a single constant.
+func (b *Builder) product(nodes [](*Node)) *Node {
+	factor, folded := 1., 0
+	var rest [](*Node)
+	for _, n := range nodes {
+		if b.constant(n) {
+			factor *= n.Val
+			folded++
+		} else {
+			Append(&rest, n)
+		}
+	}
+	if folded > 0 && (factor != 1 || len(rest) == 0) {
+		rest = append([](*Node){b.Const(factor)}, rest...)
+	}
+	if len(rest) == 1 {
+		return rest[0]
+	}
+	return b.Mul(rest...)
+}
+
+// constant reports whether n is a constant of the builder.
+func (b *Builder) constant(n *Node) bool {
+	return n.Op == "" && len(n.Inputs) == 0 && !contains(b.inputs, n)
+}
+
+// isConst reports whether n is a constant of the builder with value val.
+func (b *Builder) isConst(n *Node, val float64) bool {
+	return b.constant(n) && n.Val == val
+}
+
+func (b *Builder) neg(x *Node) *Node {
+	return b.Mul(b.Const(-1), x)
+}
+
+// sign is 1 for x > 0, -1 for x < 0 and 0 at 0, like the partial of Abs.
+func (b *Builder) sign(x *Node) *Node {
+	return b.Add(b.Op(opHeaviside, 0, x), b.neg(b.Op(opHeaviside, 0, b.neg(x))))
+}
+
+func (b *Builder) normalPDF(x *Node) *Node {
+	return b.Mul(b.Const(1/math.Sqrt(2*math.Pi)), b.Exp(b.Mul(b.Const(-0.5), x, x)))
+}
+
+func ones(b *Builder, n int) [](*Node) {
+	partials := make([](*Node), n)
+	for i := range partials {
+		partials[i] = b.Const(1)
+	}
+	return partials
+}
+
+func init() {
+	builtins := map[Op]Derivative{
+		Add: func(b *Builder, x [](*Node), _ *Node, _ float64) [](*Node) {
+			return ones(b, len(x))
+		},
+		Multiply: func(b *Builder, x [](*Node), _ *Node, _ float64) [](*Node) {
+			if len(x) == 1 {
+				return ones(b, 1)
+			}
+			partials := make([](*Node), len(x))
+			for i := range x {
+				others := append(append([](*Node){}, x[:i]...), x[i+1:]...)
+				partials[i] = b.product(others)
+			}
+			return partials
+		},
+		Dot: func(_ *Builder, x [](*Node), _ *Node, _ float64) [](*Node) {
+			d := len(x) / 2
+			return append(append([](*Node){}, x[d:]...), x[:d]...)
+		},
+		Pow: func(b *Builder, x [](*Node), y *Node, param float64) [](*Node) {
+			if len(x) == 2 {
+				// e * x^(e-1) and y * log(x), the latter 0 unless x > 0
+				e := x[1]
+				return [](*Node){
+					b.Mul(e, b.PowVar(x[0], b.Add(e, b.Const(-1)))),
+					b.XLogY(b.Mul(y, b.Op(opHeaviside, 0, x[0])), x[0]),
+				}
+			}
+			if param == 0 {
+				return [](*Node){nil}
+			}
+			return [](*Node){b.Mul(b.Const(param), b.Pow(x[0], param-1))}
+		},
+		Relu: func(b *Builder, x [](*Node), _ *Node, _ float64) [](*Node) {
+			return [](*Node){b.Op(opHeaviside, 0, x[0])}
+		},
+		Exp: func(_ *Builder, _ [](*Node), y *Node, _ float64) [](*Node) {
+			return [](*Node){y}
+		},
+		Reciprocal: func(b *Builder, _ [](*Node), y *Node, _ float64) [](*Node) {
+			return [](*Node){b.Mul(b.Const(-1), y, y)}
+		},
+		Log: func(b *Builder, x [](*Node), _ *Node, _ float64) [](*Node) {
+			return [](*Node){b.Reciprocal(x[0])}
+		},
+		Sqrt: func(b *Builder, _ [](*Node), y *Node, _ float64) [](*Node) {
+			return [](*Node){b.Mul(b.Const(0.5), b.Reciprocal(y))}
+		},
+		Tanh: func(b *Builder, _ [](*Node), y *Node, _ float64) [](*Node) {
+			return [](*Node){complement(b, b.Mul(y, y))}
+		},
+		Sigmoid: func(b *Builder, _ [](*Node), y *Node, _ float64) [](*Node) {
+			return [](*Node){b.Mul(y, complement(b, y))}
+		},
+		Softplus: func(b *Builder, x [](*Node), _ *Node, _ float64) [](*Node) {
+			return [](*Node){b.Sigmoid(x[0])}
+		},
+		Gelu: func(b *Builder, x [](*Node), _ *Node, _ float64) [](*Node) {
+			// Φ(x) + x * φ(x)
+			return [](*Node){b.Add(b.Op(opNormalCDF, 0, x[0]), b.Mul(x[0], b.normalPDF(x[0])))}
+		},
+		Elu: func(b *Builder, x [](*Node), y *Node, alpha float64) [](*Node) {
+			// 1 for x > 0 and y + alpha otherwise
+			below := b.Add(y, b.Const(alpha))
+			h := b.Op(opHeaviside, 0, x[0])
+			return [](*Node){b.Add(b.Mul(h, complement(b, below)), below)}
+		},
+		LeakyRelu: func(b *Builder, x [](*Node), _ *Node, slope float64) [](*Node) {
+			return [](*Node){b.Op(opHeaviside, slope, x[0])}
+		},
+		Abs: func(b *Builder, x [](*Node), _ *Node, _ float64) [](*Node) {
+			return [](*Node){b.sign(x[0])}
+		},
+		Huber: func(b *Builder, x [](*Node), _ *Node, delta float64) [](*Node) {
+			// x clamped to [-delta, delta], with min(a, b) = -max(-a, -b)
+			below := b.Max(b.Const(-delta), b.neg(x[0]))
+			return [](*Node){b.Max(b.Const(-delta), b.neg(below))}
+		},
+		XLogY: func(b *Builder, x [](*Node), _ *Node, _ float64) [](*Node) {
+			// log(y) and x / y, both 0 unless y > 0
+			return [](*Node){
+				b.XLogY(b.Op(opHeaviside, 0, x[1]), x[1]),
+				b.Mul(x[0], b.Op(opPositiveReciprocal, 0, x[1])),
+			}
+		},
+		Maximum: func(b *Builder, x [](*Node), _ *Node, _ float64) [](*Node) {
+			partials := make([](*Node), len(x))
+			for i := range x {
+				partials[i] = b.Op(opIsMax, float64(i), x...)
+			}
+			return partials
+		},
+		opHeaviside: func(_ *Builder, _ [](*Node), _ *Node, _ float64) [](*Node) {
+			return [](*Node){nil}
+		},
+		opNormalCDF: func(b *Builder, x [](*Node), _ *Node, _ float64) [](*Node) {
+			return [](*Node){b.normalPDF(x[0])}
+		},
+		opIsMax: func(_ *Builder, x [](*Node), _ *Node, _ float64) [](*Node) {
+			return make([](*Node), len(x))
+		},
+		opPositiveReciprocal: func(b *Builder, _ [](*Node), y *Node, _ float64) [](*Node) {
+			return [](*Node){b.Mul(b.Const(-1), y, y)}
+		},
+		SoftMaxCrossEntropy: func(b *Builder, x [](*Node), _ *Node, _ float64) [](*Node) {
+			// p * Σ targets - targets for the logits and lse - logits for
+			// the targets, with lse = m + log(Σ exp(logits - m))
+			d := len(x) / 2
+			logits, targets := x[:d], x[d:]
+			m := b.Max(logits...)
+			lse := b.Add(m, b.Log(b.Add(Map(logits, func(l *Node) *Node {
+				return b.Exp(difference(b, l, m))
+			})...)))
+			total := b.sum(targets)
+			partials := make([](*Node), len(x))
+			for i, l := range logits {
+				p := b.Exp(difference(b, l, lse))
+				partials[i] = difference(b, b.Mul(p, total), targets[i])
+				partials[d+i] = difference(b, lse, l)
+			}
+			return partials
+		},
+	}
+	for op, d := range builtins {
+		Panic(RegisterDerivative(op, d))
+	}
+}
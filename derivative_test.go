@@ -0,0 +1,165 @@
+package nngo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the gradient graph of every built-in op gives the gradients of Backprop,
+// and backpropagating it gives second derivatives
+func TestGradGraph1(t *testing.T) {
+	for _, c := range opCases {
+		g := opGraph(c.op, c.param, len(c.inputs))
+		d, err := g.GradGraph("d")
+		Panic(err)
+		assert.NoError(t, d.Validate(), c.op)
+		assert.Equal(t, "f-output-0-upstream", d.Inputs[len(c.inputs)].Label, c.op)
+
+		inputs := append(append([]float64{}, c.inputs...), 1)
+		Panic(d.Forward(inputs))
+		Panic(g.Forward(c.inputs))
+		g.ZeroGrad()
+		Panic(g.Backprop([]float64{1}))
+		assert.InDeltaSlice(t, inputGrads(g), outputVals(d), 1e-12, c.op)
+
+		// Hessian-vector products against differences of the gradients
+		v := make([]float64, len(c.inputs))
+		for i := range v {
+			v[i] = 1 - 0.5*float64(i)
+		}
+		mismatches, err := GradCheck(&d, inputs, v, 1e-6, 1e-5)
+		Panic(err)
+		assert.Empty(t, mismatches, c.op)
+	}
+}
+
+// f(x, y) = x² y + exp(x y)
+func TestGradGraph2(t *testing.T) {
+	b := NewBuilder("f")
+	x, y := b.Input("x"), b.Input("y")
+	g := b.Build(b.Add(b.Mul(x, x, y), b.Exp(b.Mul(x, y))))
+	d, err := g.GradGraph("d")
+	Panic(err)
+
+	Panic(d.Forward([]float64{1, 2, 1}))
+	e := math.Exp(2)
+	assert.InDeltaSlice(t, []float64{4 + 2*e, 1 + e}, outputVals(d), 1e-12)
+
+	// H = [[2y + y² e, 2x + e + x y e], [2x + e + x y e, x² e]]
+	d.ZeroGrad()
+	Panic(d.Backprop([]float64{1, -1}))
+	hxx, hxy, hyy := 4+4*e, 2+3*e, e
+	assert.InDelta(t, hxx-hxy, d.Inputs[0].Grad, 1e-9)
+	assert.InDelta(t, hxy-hyy, d.Inputs[1].Grad, 1e-9)
+	// and the upstream gradient gets ∇f · v
+	assert.InDelta(t, (4+2*e)-(1+e), d.Inputs[2].Grad, 1e-9)
+}
+
+// Newton's method on f(x) = x⁴ / 4 - 2x, whose minimum is at 2^(1/3)
+func TestGradGraph3(t *testing.T) {
+	b := NewBuilder("f")
+	x := b.Input("x")
+	g := b.Build(b.Add(b.Mul(b.Const(0.25), b.Pow(x, 4)), b.Mul(b.Const(-2), x)))
+	d, err := g.GradGraph("d")
+	Panic(err)
+
+	val := 1.
+	for i := 0; i < 10; i++ {
+		Panic(d.Forward([]float64{val, 1}))
+		d.ZeroGrad()
+		Panic(d.Backprop([]float64{1}))
+		val -= d.Outputs[0].Val / d.Inputs[0].Grad
+	}
+	assert.InDelta(t, math.Cbrt(2), val, 1e-12)
+
+	// the unit node of a linear layer becomes a constant
+	linear := NewLinearInit(2, 1, "l", rand.New(rand.NewSource(42)))
+	d, err = linear.Graph.GradGraph("d")
+	Panic(err)
+	Panic(d.Forward([]float64{1, 2, 3, 4, 5, 1}))
+	assert.Equal(t, []float64{3, 4, 1, 2, 1}, outputVals(d))
+
+	var a Node
+	in := InputSymbol("in", [](*Node){&a})
+	out := OutputSymbol("out", &a)
+	a = Node{Label: "a", Op: "missing", Inputs: [](*Node){&in}, Outputs: [](*Node){&out}}
+	missing := NewGraph([](*Node){&in}, [](*Node){&out}, [](*Node){&a})
+	_, err = missing.GradGraph("d")
+	assert.ErrorContains(t, err, "no registered derivative")
+	assert.Error(t, RegisterDerivative(Exp, nil))
+}
+
+// partials that ops.go guards are 0 in the gradient graph too, rather than
+// failing outside of the domain of log
+func TestGradGraph4(t *testing.T) {
+	// KL divergence with a one-hot target, whose y log y is at y = 0
+	kl := KLDivLoss(2, "kl")
+	inputs := []float64{0.3, 0.7, 0, 1}
+	d, err := kl.GradGraph("d")
+	Panic(err)
+	Panic(d.Forward(append(append([]float64{}, inputs...), 1)))
+	Panic(kl.Forward(inputs))
+	kl.ZeroGrad()
+	Panic(kl.Backprop([]float64{1}))
+	assert.InDeltaSlice(t, inputGrads(kl), outputVals(d), 1e-12)
+
+	hess, err := kl.Hessian(inputs)
+	Panic(err)
+	assertMatrix(t, [][]float64{
+		{0, 0, -1 / 0.3, 0},
+		{0, 1 / 0.49, 0, -1 / 0.7},
+		{-1 / 0.3, 0, 0, 0},
+		{0, -1 / 0.7, 0, 1},
+	}, hess, 1e-9)
+
+	// x^e with a negative base, where the partial for e is 0
+	b := NewBuilder("f")
+	x, e := b.Input("x"), b.Input("e")
+	g := b.Build(b.PowVar(x, e))
+	d, err = g.GradGraph("d")
+	Panic(err)
+	Panic(d.Forward([]float64{-2, 3, 1}))
+	assert.InDeltaSlice(t, []float64{12, 0}, outputVals(d), 1e-12)
+
+	hess, err = g.Hessian([]float64{-2, 3})
+	Panic(err)
+	assertMatrix(t, [][]float64{{-12, 4}, {0, 0}}, hess, 1e-9)
+}
+
+// f(x, y) = 2 * 3 * x * y + 0.5 * x * x, whose partials are built from
+// several constant factors
+func TestGradGraph5(t *testing.T) {
+	b := NewBuilder("f")
+	x, y := b.Input("x"), b.Input("y")
+	g := b.Build(b.Add(
+		b.Mul(b.Const(2), b.Const(3), x, y),
+		b.Mul(b.Const(0.5), x, x),
+	))
+	d, err := g.GradGraph("d")
+	Panic(err)
+	assert.NoError(t, d.Validate())
+
+	inputs := []float64{1.5, -2}
+	Panic(d.Forward(append(append([]float64{}, inputs...), 1)))
+	Panic(g.Forward(inputs))
+	g.ZeroGrad()
+	Panic(g.Backprop([]float64{1}))
+	assert.InDeltaSlice(t, inputGrads(g), outputVals(d), 1e-12)
+	assert.InDeltaSlice(t, []float64{6*-2 + 1.5, 6 * 1.5}, outputVals(d), 1e-12)
+
+	hess, err := g.Hessian(inputs)
+	Panic(err)
+	assertMatrix(t, [][]float64{{1, 6}, {6, 0}}, hess, 1e-12)
+
+	// x * 2 * 3 alone
+	b = NewBuilder("f")
+	x = b.Input("x")
+	g = b.Build(b.Mul(x, b.Const(2), b.Const(3)))
+	d, err = g.GradGraph("d")
+	Panic(err)
+	Panic(d.Forward([]float64{1, 1}))
+	assert.Equal(t, []float64{6}, outputVals(d))
+}
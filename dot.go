@@ -0,0 +1,160 @@
+package nngo
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DOTOptions controls what Graph.WriteDOT draws.
+type DOTOptions struct {
+	// Values and Grads add the current Val and Grad of every node.
+	Values bool
+	Grads  bool
+	// Params are drawn apart from the other inputs, e.g. Module.Params.
+	Params [](*Node)
+}
+
+// Colors of the nodes drawn by WriteDOT.
+const (
+	dotInputColor  = "lightblue"
+	dotParamColor  = "gold"
+	dotOutputColor = "lightgreen"
+	dotConstColor  = "lightgrey"
+	dotOpColor     = "white"
+)
+
+// WriteDOT writes the graph to w in the DOT language of GraphViz, one box per
+// node showing its Label and Op. Inputs, Params and outputs are colored
+// differently, and each of the Subgraphs combined by Merge, Compose, Parallel
+// or NewSequential is drawn as a cluster. Nodes the graph does not list but
+// that feed its nodes, directly or not, are drawn too, those without inputs
+// like the unit node of NewLinear as constants.
+func (g *Graph) WriteDOT(w io.Writer, opts DOTOptions) (err error) {
+	var nodes [](*Node)
+	drawn := Set[*Node]{}
+	for _, list := range [][](*Node){g.Inputs, g.Intermediates, g.Outputs} {
+		for _, n := range list {
+			if !drawn[n] {
+				drawn[n] = true
+				Append(&nodes, n)
+			}
+		}
+	}
+	// nodes appended here are walked in turn, so feeders of feeders are
+	// drawn too
+	for i := 0; i < len(nodes); i++ {
+		for _, inp := range nodes[i].Inputs {
+			if !drawn[inp] {
+				drawn[inp] = true
+				Append(&nodes, inp)
+			}
+		}
+	}
+	ids := map[*Node]string{}
+	for i, n := range nodes {
+		ids[n] = fmt.Sprintf("n%d", i)
+	}
+
+	colors := map[*Node]string{}
+	for _, n := range g.Inputs {
+		colors[n] = dotInputColor
+	}
+	for _, n := range opts.Params {
+		colors[n] = dotParamColor
+	}
+	for _, n := range g.Outputs {
+		colors[n] = dotOutputColor
+	}
+	statement := func(n *Node) string {
+		lines := []string{n.Label}
+		if n.Op != "" {
+			op := string(n.Op)
+			if opsWithParam[n.Op] || n.Param != 0 {
+				op = fmt.Sprintf("%s(%s)", n.Op, formatFloat(n.Param))
+			}
+			Append(&lines, op)
+		}
+		if opts.Values {
+			Append(&lines, fmt.Sprintf("val = %g", n.Val))
+		}
+		if opts.Grads {
+			Append(&lines, fmt.Sprintf("grad = %g", n.Grad))
+		}
+		color, ok := colors[n]
+		if !ok {
+			color = dotOpColor
+			if n.Op == "" && len(n.Inputs) == 0 {
+				color = dotConstColor
+			}
+		}
+		return fmt.Sprintf("%s [label=%s, fillcolor=%s];", ids[n], dotQuote(strings.Join(lines, "\n")), color)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("digraph {\n")
+	sb.WriteString("\trankdir=LR;\n")
+	sb.WriteString("\tnode [shape=box, style=\"rounded,filled\"];\n")
+	clustered := Set[*Node]{}
+	for i, sub := range g.Subgraphs {
+		fmt.Fprintf(&sb, "\tsubgraph cluster_%d {\n", i)
+		fmt.Fprintf(&sb, "\t\tlabel=%s;\n", dotQuote(commonPrefix(sub)))
+		for _, n := range sub {
+			if drawn[n] && !clustered[n] {
+				clustered[n] = true
+				fmt.Fprintf(&sb, "\t\t%s\n", statement(n))
+			}
+		}
+		sb.WriteString("\t}\n")
+	}
+	for _, n := range nodes {
+		if !clustered[n] {
+			fmt.Fprintf(&sb, "\t%s\n", statement(n))
+		}
+	}
+	for _, n := range nodes {
+		for _, inp := range n.Inputs {
+			fmt.Fprintf(&sb, "\t%s -> %s;\n", ids[inp], ids[n])
+		}
+	}
+	sb.WriteString("}\n")
+
+	_, err = io.WriteString(w, sb.String())
+	return
+}
+
+// WriteDOT writes the graph of the module like Graph.WriteDOT, drawing its
+// Params as parameters.
+func (m *Module) WriteDOT(w io.Writer, opts DOTOptions) error {
+	opts.Params = m.Params
+	return m.Graph.WriteDOT(w, opts)
+}
+
+// dotQuote returns s as a DOT string, with line breaks centering the lines.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// commonPrefix returns the part of the labels of nodes before the last "-"
+// they share, like the label given to the Builder or constructor that made
+// them.
+func commonPrefix(nodes [](*Node)) string {
+	if len(nodes) == 0 {
+		return ""
+	}
+	prefix := nodes[0].Label
+	for _, n := range nodes[1:] {
+		i := 0
+		for i < len(prefix) && i < len(n.Label) && prefix[i] == n.Label[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	if i := strings.LastIndex(prefix, "-"); i >= 0 {
+		return prefix[:i]
+	}
+	return prefix
+}
@@ -0,0 +1,87 @@
+package nngo
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDOT1(t *testing.T) {
+	b := NewBuilder("f")
+	x, y := b.Input("x"), b.Input("y")
+	g := b.Build(b.Mul(b.Add(x, y), b.Const(2)))
+	Panic(g.Forward([]float64{1, 2}))
+	Panic(g.Backprop([]float64{1}))
+
+	var sb strings.Builder
+	assert.NoError(t, g.WriteDOT(&sb, DOTOptions{Values: true, Grads: true}))
+	assert.Equal(t, `digraph {
+	rankdir=LR;
+	node [shape=box, style="rounded,filled"];
+	n0 [label="x\nval = 1\ngrad = 2", fillcolor=lightblue];
+	n1 [label="y\nval = 2\ngrad = 2", fillcolor=lightblue];
+	n2 [label="f-+-0\n+\nval = 3\ngrad = 2", fillcolor=white];
+	n3 [label="f-const-1\nval = 2\ngrad = 3", fillcolor=lightgrey];
+	n4 [label="f-*-2\n*\nval = 6\ngrad = 1", fillcolor=white];
+	n5 [label="f-output-0\nval = 6\ngrad = 1", fillcolor=lightgreen];
+	n0 -> n2;
+	n1 -> n2;
+	n2 -> n4;
+	n3 -> n4;
+	n4 -> n5;
+}
+`, sb.String())
+}
+
+func TestWriteDOT2(t *testing.T) {
+	g := Merge([]Graph{SoftMax(2, "s1"), SoftMax(2, "s2")})
+	var sb strings.Builder
+	assert.NoError(t, g.WriteDOT(&sb, DOTOptions{}))
+	dot := sb.String()
+
+	// one cluster per merged graph, with the wires between them outside
+	assert.Equal(t, 2, strings.Count(dot, "subgraph cluster_"))
+	s1 := dot[strings.Index(dot, "cluster_0"):strings.Index(dot, "cluster_1")]
+	assert.Contains(t, s1, `label="s1";`)
+	assert.Contains(t, s1, `n0 [label="s1-input-0", fillcolor=lightblue];`)
+	assert.Contains(t, s1, `n13 [label="s1-output-0", fillcolor=white];`)
+	assert.NotContains(t, s1, "s2-")
+	assert.Contains(t, dot, `n28 [label="s2-output-0", fillcolor=lightgreen];`)
+	assert.Contains(t, dot, "n13 -> n15;")
+	assert.NotContains(t, dot, "val =")
+	assert.Equal(t, 40, strings.Count(dot, " -> "))
+}
+
+func TestWriteDOT3(t *testing.T) {
+	mlp := NewMLPInit([]int{2, 2, 1}, Tanh, 0, "mlp", rand.New(rand.NewSource(42)))
+	var sb strings.Builder
+	assert.NoError(t, mlp.WriteDOT(&sb, DOTOptions{}))
+	dot := sb.String()
+
+	assert.Equal(t, len(mlp.Layers), strings.Count(dot, "subgraph cluster_"))
+	assert.Equal(t, len(mlp.Params), strings.Count(dot, "fillcolor=gold"))
+	assert.Equal(t, len(mlp.dataInputs()), strings.Count(dot, "fillcolor=lightblue"))
+	assert.Equal(t, 1, strings.Count(dot, "fillcolor=lightgreen"))
+}
+
+// f = x * exp(c), with neither c nor exp(c) listed by the graph
+func TestWriteDOT4(t *testing.T) {
+	var c, e, m Node
+	x := InputSymbol("x", [](*Node){&m})
+	f := OutputSymbol("f", &m)
+	c = Node{Label: "c", Outputs: [](*Node){&e}}
+	e = ExpNode("e", [](*Node){&m}, &c)
+	m = MultiplyNode("m", [](*Node){&f}, [](*Node){&x, &e})
+	graph := NewGraph([](*Node){&x}, [](*Node){&f}, [](*Node){&m})
+
+	var sb strings.Builder
+	assert.NoError(t, graph.WriteDOT(&sb, DOTOptions{}))
+	dot := sb.String()
+	assert.Contains(t, dot, `n3 [label="e\nexp", fillcolor=white];`)
+	assert.Contains(t, dot, `n4 [label="c", fillcolor=lightgrey];`)
+	assert.Contains(t, dot, "n4 -> n3;")
+	assert.NotContains(t, dot, " -> ;")
+	assert.NotContains(t, dot, "\t -> ")
+}
@@ -0,0 +1,71 @@
+package nngo
+
+import "fmt"
+
+// ComputeTangent sets the tangent of the node from the tangents of its
+// inputs, Σ ∂Val/∂input * input.Tangent, using the partials of its operator.
+// It expects Val to be up to date.
+func (n *Node) ComputeTangent() {
+	if n.Op == "" {
+		if len(n.Inputs) == 1 {
+			n.Tangent = n.Inputs[0].Tangent
+		}
+		return
+	}
+	op, ok := LookupOperator(n.Op)
+	if !ok {
+		return
+	}
+	vals := n.inputVals()
+	partials := make([]float64, len(vals))
+	op.Backward(vals, n.Val, n.Param, partials)
+	n.Tangent = 0
+	for i, inp := range n.Inputs {
+		n.Tangent += partials[i] * inp.Tangent
+	}
+}
+
+// JVP evaluates the graph at inputValues in forward mode, carrying the
+// tangent of every node along with its value, and returns the tangents of
+// the outputs: the Jacobian of the graph times tangents. It takes a single
+// forward sweep and leaves Grad untouched, so it is the cheaper way to
+// differentiate graphs with fewer inputs than outputs.
+func (g *Graph) JVP(inputValues, tangents []float64) (jvp []float64, err error) {
+	if len(tangents) != len(g.Inputs) {
+		err = fmt.Errorf("error list of tangents must match list of inputs")
+		return
+	}
+	if g.Strict {
+		err = g.Validate()
+		if err != nil {
+			return
+		}
+	}
+	err = g.SetInputs(inputValues)
+	if err != nil {
+		return
+	}
+	isInput := Set[*Node]{}
+	for i, n := range g.Inputs {
+		n.Tangent = tangents[i]
+		isInput[n] = true
+	}
+
+	for _, n := range g.Schedule() {
+		for _, inp := range n.Inputs {
+			// leaves outside of the inputs, like constants, are fixed
+			if len(inp.Inputs) == 0 && !isInput[inp] {
+				inp.Tangent = 0
+			}
+		}
+		err = n.ComputeVal()
+		if err != nil {
+			return
+		}
+		n.ComputeTangent()
+	}
+	jvp = Map(g.Outputs, func(n *Node) float64 {
+		return n.Tangent
+	})
+	return
+}
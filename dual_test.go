@@ -0,0 +1,115 @@
+package nngo
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a point of every built-in op away from its kinks
+var opCases = []struct {
+	op     Op
+	param  float64
+	inputs []float64
+}{
+	{Add, 0, []float64{1, -2, 3}},
+	{Multiply, 0, []float64{1.5, -2, 0.5}},
+	{Relu, 0, []float64{0.7}},
+	{Exp, 0, []float64{0.3}},
+	{Dot, 0, []float64{1, 2, -3, 4}},
+	{Reciprocal, 0, []float64{-0.8}},
+	{Log, 0, []float64{2.5}},
+	{Pow, 3, []float64{1.2}},
+	{Pow, 0, []float64{1.2, 2.5}},
+	{Sqrt, 0, []float64{2}},
+	{Tanh, 0, []float64{0.4}},
+	{Sigmoid, 0, []float64{-1}},
+	{Softplus, 0, []float64{0.6}},
+	{Gelu, 0, []float64{-0.3}},
+	{Elu, 0.5, []float64{-0.7}},
+	{LeakyRelu, 0.1, []float64{-2}},
+	{Abs, 0, []float64{-1.5}},
+	{Huber, 1, []float64{0.4}},
+	{Huber, 1, []float64{-3}},
+	{XLogY, 0, []float64{0.5, 2}},
+	{Maximum, 0, []float64{1, 3, 2}},
+	{SoftMaxCrossEntropy, 0, []float64{1, -1, 0.5, 0.2, 0.3, 0.5}},
+	{opHeaviside, 0.5, []float64{-1}},
+	{opNormalCDF, 0, []float64{0.3}},
+	{opIsMax, 1, []float64{1, 3, 2}},
+	{opPositiveReciprocal, 0, []float64{0.8}},
+}
+
+// opGraph applies op to n inputs labelled x0, x1, ...
+func opGraph(op Op, param float64, n int) Graph {
+	b := NewBuilder("f")
+	inputs := make([](*Node), n)
+	for i := range inputs {
+		inputs[i] = b.Input(fmt.Sprintf("x%d", i))
+	}
+	return b.Build(b.Op(op, param, inputs...))
+}
+
+// every built-in op against central differences along the tangent
+func TestJVP1(t *testing.T) {
+	for _, c := range opCases {
+		g := opGraph(c.op, c.param, len(c.inputs))
+
+		tangents := make([]float64, len(c.inputs))
+		for i := range tangents {
+			tangents[i] = 0.5 + 0.25*float64(i)
+		}
+		jvp, err := g.JVP(c.inputs, tangents)
+		Panic(err)
+		val := g.Outputs[0].Val
+
+		eps := 1e-6
+		x := make([]float64, len(c.inputs))
+		for i := range x {
+			x[i] = c.inputs[i] + eps*tangents[i]
+		}
+		Panic(g.Forward(x))
+		hi := g.Outputs[0].Val
+		for i := range x {
+			x[i] = c.inputs[i] - eps*tangents[i]
+		}
+		Panic(g.Forward(x))
+		lo := g.Outputs[0].Val
+		assert.InDelta(t, (hi-lo)/(2*eps), jvp[0], 1e-6, c.op)
+
+		Panic(g.Forward(c.inputs))
+		assert.Equal(t, val, g.Outputs[0].Val, c.op)
+	}
+}
+
+// one forward sweep gives the sensitivity of every softmax output to the
+// first logit, a column of the Jacobian
+func TestJVP2(t *testing.T) {
+	s := SoftMax(3, "s")
+	jvp, err := s.JVP([]float64{1, 2, 3}, []float64{1, 0, 0})
+	Panic(err)
+	expSum := math.Exp(1) + math.Exp(2) + math.Exp(3)
+	p := []float64{math.Exp(1) / expSum, math.Exp(2) / expSum, math.Exp(3) / expSum}
+	assert.InDeltaSlice(t, []float64{p[0] * (1 - p[0]), -p[1] * p[0], -p[2] * p[0]}, jvp, 1e-12)
+	assert.InDeltaSlice(t, p, outputVals(s), 1e-12)
+
+	// agrees with reverse mode: u · (J v) = (uᵀ J) · v
+	u, v := []float64{1, -2, 0.5}, []float64{0.3, -1, 2}
+	jvp, err = s.JVP([]float64{1, 2, 3}, v)
+	Panic(err)
+	s.ZeroGrad()
+	Panic(s.Backprop(u))
+	assert.InDelta(t, DotProduct(u, jvp), DotProduct(inputGrads(s), v), 1e-12)
+
+	// constants of a builder and the unit node of a linear layer are fixed
+	linear := NewLinearInit(2, 1, "l", rand.New(rand.NewSource(42)))
+	jvp, err = linear.Graph.JVP([]float64{1, 2, 3, 4, 5}, []float64{1, 0, 0, 0, 0})
+	Panic(err)
+	assert.Equal(t, []float64{3}, jvp)
+
+	_, err = s.JVP([]float64{1, 2, 3}, []float64{1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package nngo
+
+import (
+	"fmt"
+	"math"
+)
+
+// GradMismatch is an input whose gradient from Backprop disagrees with its
+// finite-difference estimate.
+type GradMismatch struct {
+	Node     *Node
+	Analytic float64
+	Numeric  float64
+}
+
+func (m GradMismatch) String() string {
+	return fmt.Sprintf("%s: backprop %g, finite differences %g", m.Node.Label, m.Analytic, m.Numeric)
+}
+
+// GradCheck compares the gradient Backprop computes for every input of g,
+// which for a Module includes its parameters, with central differences of
+// Σ upstream[i] * Outputs[i] taken with a step of eps. An input mismatches
+// when the two differ by more than tol * max(1, |analytic|, |numeric|).
+//
+// Afterwards the graph holds the values and gradients at inputs.
+func GradCheck(g *Graph, inputs, upstream []float64, eps, tol float64) (mismatches []GradMismatch, err error) {
+	if len(upstream) != len(g.Outputs) {
+		err = fmt.Errorf("error list of upstream gradients must match list of outputs")
+		return
+	}
+	weighted := func(x []float64) (sum float64, err error) {
+		err = g.Forward(x)
+		for i, out := range g.Outputs {
+			sum += upstream[i] * out.Val
+		}
+		return
+	}
+
+	x := append([]float64{}, inputs...)
+	numeric := make([]float64, len(x))
+	for i := range x {
+		x[i] = inputs[i] + eps
+		hi, err := weighted(x)
+		if err != nil {
+			return nil, err
+		}
+		x[i] = inputs[i] - eps
+		lo, err := weighted(x)
+		if err != nil {
+			return nil, err
+		}
+		x[i] = inputs[i]
+		numeric[i] = (hi - lo) / (2 * eps)
+	}
+
+	err = g.Forward(inputs)
+	if err != nil {
+		return
+	}
+	g.ZeroGrad()
+	err = g.Backprop(upstream)
+	if err != nil {
+		return
+	}
+	for i, n := range g.Inputs {
+		analytic := n.Grad
+		scale := Max(1, math.Abs(analytic), math.Abs(numeric[i]))
+		if !(math.Abs(analytic-numeric[i]) <= tol*scale) {
+			Append(&mismatches, GradMismatch{Node: n, Analytic: analytic, Numeric: numeric[i]})
+		}
+	}
+	return
+}
@@ -0,0 +1,64 @@
+package nngo_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"nngo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const badSquare nngo.Op = "test-bad-square"
+
+func init() {
+	// x² with the derivative x instead of 2x
+	nngo.Panic(nngo.RegisterOperator(nngo.NewOperator(
+		badSquare,
+		1,
+		func(inputs []float64, _ float64) (float64, error) {
+			return inputs[0] * inputs[0], nil
+		},
+		func(inputs []float64, _, _ float64, partials []float64) {
+			partials[0] = inputs[0]
+		},
+	)))
+}
+
+func TestGradCheck1(t *testing.T) {
+	s := nngo.SoftMax(3, "s")
+	mismatches, err := nngo.GradCheck(&s, []float64{1, 2, 3}, []float64{1, 1.5, 2}, 1e-6, 1e-6)
+	nngo.Panic(err)
+	assert.Empty(t, mismatches)
+
+	// the parameters of a module are inputs of its graph
+	mlp := nngo.NewMLPInit([]int{2, 3, 1}, nngo.Tanh, 0, "mlp", rand.New(rand.NewSource(42)))
+	nngo.Panic(mlp.SetWeights([]float64{0.5, -1, 0.2, 1, 0.3, 0.1, -0.4, 0.7, -0.2, 1, -1, 0.5, 0.3}))
+	inputs := append([]float64{0.5, -2}, mlp.Weights()...)
+	mismatches, err = nngo.GradCheck(&mlp.Graph, inputs, []float64{1}, 1e-6, 1e-6)
+	nngo.Panic(err)
+	assert.Empty(t, mismatches)
+	assert.Len(t, mlp.Graph.Inputs, 15)
+}
+
+// f(x, y) = badSquare(x) * y
+func TestGradCheck2(t *testing.T) {
+	b := nngo.NewBuilder("f")
+	x, y := b.Input("x"), b.Input("y")
+	g := b.Build(b.Mul(b.Op(badSquare, 0, x), y))
+
+	mismatches, err := nngo.GradCheck(&g, []float64{3, 2}, []float64{1}, 1e-6, 1e-6)
+	nngo.Panic(err)
+	assert.Len(t, mismatches, 1)
+	assert.Equal(t, x, mismatches[0].Node)
+	assert.InDelta(t, 6, mismatches[0].Analytic, 1e-12)
+	assert.InDelta(t, 12, mismatches[0].Numeric, 1e-6)
+	assert.Contains(t, mismatches[0].String(), "x: backprop 6")
+
+	// the graph is left at the inputs that were checked
+	assert.Equal(t, 18.0, g.Outputs[0].Val)
+	assert.Equal(t, 9.0, y.Grad)
+
+	_, err = nngo.GradCheck(&g, []float64{3, 2}, []float64{1, 1}, 1e-6, 1e-6)
+	assert.Error(t, err)
+}
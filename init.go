@@ -0,0 +1,163 @@
+package nngo
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Initializer fills the values of a parameter group. Weight matrices have
+// the shape [fanOut, fanIn], so one row holds the weights of one output.
+type Initializer interface {
+	Init(vals []float64, shape []int, randSource *rand.Rand)
+}
+
+// fans returns the number of inputs and outputs of a parameter of the given
+// shape.
+func fans(shape []int) (fanIn, fanOut int) {
+	switch len(shape) {
+	case 0:
+		return 1, 1
+	case 1:
+		return shape[0], shape[0]
+	}
+	receptive := Product(shape[2:])
+	return shape[1] * receptive, shape[0] * receptive
+}
+
+type Uniform struct {
+	Low, High float64
+}
+
+func (u Uniform) Init(vals []float64, _ []int, randSource *rand.Rand) {
+	for i := range vals {
+		vals[i] = RandomFloat64(randSource, u.Low, u.High)
+	}
+}
+
+type Constant struct {
+	Value float64
+}
+
+func (c Constant) Init(vals []float64, _ []int, _ *rand.Rand) {
+	for i := range vals {
+		vals[i] = c.Value
+	}
+}
+
+// ZeroInit is the usual initializer for biases.
+var ZeroInit = Constant{0}
+
+// XavierUniform draws from U(-a, a) with a = sqrt(6 / (fanIn + fanOut)).
+type XavierUniform struct{}
+
+func (XavierUniform) Init(vals []float64, shape []int, randSource *rand.Rand) {
+	fanIn, fanOut := fans(shape)
+	a := math.Sqrt(6 / float64(fanIn+fanOut))
+	Uniform{-a, a}.Init(vals, shape, randSource)
+}
+
+// XavierNormal draws from N(0, 2 / (fanIn + fanOut)).
+type XavierNormal struct{}
+
+func (XavierNormal) Init(vals []float64, shape []int, randSource *rand.Rand) {
+	fanIn, fanOut := fans(shape)
+	std := math.Sqrt(2 / float64(fanIn+fanOut))
+	for i := range vals {
+		vals[i] = randSource.NormFloat64() * std
+	}
+}
+
+// HeUniform draws from U(-a, a) with a = sqrt(6 / fanIn), suited to layers
+// followed by a ReLU.
+type HeUniform struct{}
+
+func (HeUniform) Init(vals []float64, shape []int, randSource *rand.Rand) {
+	fanIn, _ := fans(shape)
+	a := math.Sqrt(6 / float64(fanIn))
+	Uniform{-a, a}.Init(vals, shape, randSource)
+}
+
+// HeNormal draws from N(0, 2 / fanIn).
+type HeNormal struct{}
+
+func (HeNormal) Init(vals []float64, shape []int, randSource *rand.Rand) {
+	fanIn, _ := fans(shape)
+	std := math.Sqrt(2 / float64(fanIn))
+	for i := range vals {
+		vals[i] = randSource.NormFloat64() * std
+	}
+}
+
+// TruncatedNormal draws from N(Mean, Std²), redrawing values that fall more
+// than two standard deviations from the mean.
+type TruncatedNormal struct {
+	Mean, Std float64
+}
+
+func (n TruncatedNormal) Init(vals []float64, _ []int, randSource *rand.Rand) {
+	for i := range vals {
+		z := randSource.NormFloat64()
+		for math.Abs(z) > 2 {
+			z = randSource.NormFloat64()
+		}
+		vals[i] = n.Mean + n.Std*z
+	}
+}
+
+// Orthogonal fills the parameter, seen as a matrix with shape[0] rows, with
+// orthonormal rows or columns, whichever there are fewer of, scaled by Gain.
+// A Gain of 0 is taken as 1, so the zero value is usable.
+type Orthogonal struct {
+	Gain float64
+}
+
+func (o Orthogonal) Init(vals []float64, shape []int, randSource *rand.Rand) {
+	rows := 1
+	if len(shape) > 0 {
+		rows = shape[0]
+	}
+	if rows == 0 || len(vals) == 0 {
+		return
+	}
+	gain := o.Gain
+	if gain == 0 {
+		gain = 1
+	}
+	cols := len(vals) / rows
+	// orthonormalize the shorter side of a gaussian matrix with Gram-Schmidt
+	n, m := rows, cols
+	at := func(i, j int) int {
+		return i*cols + j
+	}
+	if rows > cols {
+		n, m = cols, rows
+		at = func(i, j int) int {
+			return j*cols + i
+		}
+	}
+	for i := range vals {
+		vals[i] = randSource.NormFloat64()
+	}
+	for i := 0; i < n; i++ {
+		for k := 0; k < i; k++ {
+			dot := 0.
+			for j := 0; j < m; j++ {
+				dot += vals[at(i, j)] * vals[at(k, j)]
+			}
+			for j := 0; j < m; j++ {
+				vals[at(i, j)] -= dot * vals[at(k, j)]
+			}
+		}
+		norm := 0.
+		for j := 0; j < m; j++ {
+			norm += vals[at(i, j)] * vals[at(i, j)]
+		}
+		norm = math.Sqrt(norm)
+		for j := 0; j < m; j++ {
+			vals[at(i, j)] /= norm
+		}
+	}
+	for i := range vals {
+		vals[i] *= gain
+	}
+}
@@ -0,0 +1,143 @@
+package nngo
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func meanAndStd(vals []float64) (mean, std float64) {
+	mean = Sum(vals) / float64(len(vals))
+	for _, v := range vals {
+		std += (v - mean) * (v - mean)
+	}
+	std = math.Sqrt(std / float64(len(vals)))
+	return
+}
+
+func TestInit1(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	shape := []int{100, 300}
+	vals := make([]float64, 30000)
+
+	XavierUniform{}.Init(vals, shape, r)
+	a := math.Sqrt(6. / 400)
+	assert.LessOrEqual(t, Max(vals...), a)
+	assert.GreaterOrEqual(t, Min(vals...), -a)
+
+	XavierNormal{}.Init(vals, shape, r)
+	_, std := meanAndStd(vals)
+	assert.InEpsilon(t, math.Sqrt(2./400), std, 2e-2)
+
+	HeUniform{}.Init(vals, shape, r)
+	assert.LessOrEqual(t, Max(vals...), math.Sqrt(6./300))
+
+	HeNormal{}.Init(vals, shape, r)
+	_, std = meanAndStd(vals)
+	assert.InEpsilon(t, math.Sqrt(2./300), std, 2e-2)
+
+	TruncatedNormal{Mean: 1, Std: 0.5}.Init(vals, shape, r)
+	assert.LessOrEqual(t, Max(vals...), 2.)
+	assert.GreaterOrEqual(t, Min(vals...), 0.)
+
+	Constant{3}.Init(vals, shape, r)
+	assert.Equal(t, 3., Min(vals...))
+	assert.Equal(t, 3., Max(vals...))
+}
+
+func TestInit2(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, c := range []struct {
+		shape []int
+		init  Orthogonal
+		gain  float64
+	}{
+		{[]int{3, 5}, Orthogonal{Gain: 2}, 2},
+		{[]int{5, 3}, Orthogonal{Gain: 2}, 2},
+		{[]int{4, 4}, Orthogonal{Gain: 2}, 2},
+		{[]int{3, 4}, Orthogonal{}, 1},
+	} {
+		rows, cols := c.shape[0], c.shape[1]
+		vals := make([]float64, rows*cols)
+		c.init.Init(vals, c.shape, r)
+
+		// the shorter side is orthogonal with norm equal to the gain
+		n, m, at := rows, cols, func(i, j int) float64 { return vals[i*cols+j] }
+		if rows > cols {
+			n, m, at = cols, rows, func(i, j int) float64 { return vals[j*cols+i] }
+		}
+		for i := 0; i < n; i++ {
+			for k := 0; k < n; k++ {
+				dot := 0.
+				for j := 0; j < m; j++ {
+					dot += at(i, j) * at(k, j)
+				}
+				want := 0.
+				if i == k {
+					want = c.gain * c.gain
+				}
+				assert.InDelta(t, want, dot, 1e-9)
+			}
+		}
+	}
+
+	// an empty matrix is left alone
+	assert.NotPanics(t, func() {
+		Orthogonal{}.Init(nil, []int{0, 3}, r)
+	})
+}
+
+func TestInit3(t *testing.T) {
+	linear := NewLinearInit(3, 2, "l", rand.New(rand.NewSource(42)))
+	params := linear.Parameters()
+	assert.Equal(t, "l-weight", params[0].Name)
+	assert.Equal(t, []int{2, 3}, params[0].Shape)
+	assert.Equal(t, []int{2}, params[1].Shape)
+	// the defaults draw the weights and zero the biases
+	for _, w := range params[0].Values() {
+		assert.NotZero(t, w)
+	}
+	assert.Equal(t, []float64{0, 0}, params[1].Values())
+
+	params[1].Init = Constant{0.1}
+	linear.InitWeights(rand.New(rand.NewSource(7)))
+	assert.Equal(t, []float64{0.1, 0.1}, params[1].Values())
+	for _, w := range params[0].Values() {
+		assert.LessOrEqual(t, math.Abs(w), math.Sqrt(6./5))
+		assert.NotZero(t, w)
+	}
+
+	// weights of each output are followed by its bias
+	p := linear.Weights()
+	assert.Equal(t, params[0].Values()[:3], p[:3])
+	assert.Equal(t, 0.1, p[3])
+	assert.Equal(t, params[0].Values()[3:], p[4:7])
+	assert.Equal(t, 0.1, p[7])
+
+	Panic(linear.Forward([]float64{1, 2, 3}))
+	assert.InDelta(t, p[0]+2*p[1]+3*p[2]+0.1, linear.Graph.Outputs[0].Val, 1e-12)
+	assert.InDelta(t, p[4]+2*p[5]+3*p[6]+0.1, linear.Graph.Outputs[1].Val, 1e-12)
+}
+
+// without a source, the layers are still initialized, from a random seed
+func TestInit4(t *testing.T) {
+	linear := NewLinear(3, 2, "l")
+	for _, w := range linear.Parameters()[0].Values() {
+		assert.NotZero(t, w)
+	}
+	assert.Equal(t, []float64{0, 0}, linear.Parameters()[1].Values())
+
+	seeded := NewLinearInit(3, 2, "l", rand.New(rand.NewSource(42)))
+	again := NewLinearInit(3, 2, "l", rand.New(rand.NewSource(42)))
+	assert.Equal(t, seeded.Weights(), again.Weights())
+
+	mlp := NewMLP([]int{2, 3, 1}, Tanh, 0, "mlp")
+	for _, p := range mlp.Params {
+		if strings.Contains(p.Label, "weight") {
+			assert.NotZero(t, p.Val)
+		}
+	}
+}
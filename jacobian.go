@@ -0,0 +1,91 @@
+package nngo
+
+import "fmt"
+
+// Jacobian returns the matrix of partial derivatives of every output with
+// respect to every input at inputValues, one row per output. It takes one
+// forward mode sweep per input or one reverse mode sweep per output,
+// whichever is fewer. Gradients of the graph are zeroed.
+func (g *Graph) Jacobian(inputValues []float64) (jac [][]float64, err error) {
+	if len(g.Inputs) <= len(g.Outputs) {
+		return g.jacobianForward(inputValues)
+	}
+	return g.jacobianReverse(inputValues)
+}
+
+func (g *Graph) jacobianForward(inputValues []float64) (jac [][]float64, err error) {
+	jac = newMatrix(len(g.Outputs), len(g.Inputs))
+	tangents := make([]float64, len(g.Inputs))
+	for j := range g.Inputs {
+		tangents[j] = 1
+		column, err := g.JVP(inputValues, tangents)
+		if err != nil {
+			return nil, err
+		}
+		tangents[j] = 0
+		for i := range column {
+			jac[i][j] = column[i]
+		}
+	}
+	g.ZeroGrad()
+	return
+}
+
+func (g *Graph) jacobianReverse(inputValues []float64) (jac [][]float64, err error) {
+	jac = newMatrix(len(g.Outputs), len(g.Inputs))
+	err = g.Forward(inputValues)
+	if err != nil {
+		return
+	}
+	upstream := make([]float64, len(g.Outputs))
+	for i := range g.Outputs {
+		upstream[i] = 1
+		g.ZeroGrad()
+		err = g.Backprop(upstream)
+		if err != nil {
+			return
+		}
+		upstream[i] = 0
+		for j, n := range g.Inputs {
+			jac[i][j] = n.Grad
+		}
+	}
+	g.ZeroGrad()
+	return
+}
+
+// Hessian returns the matrix of second derivatives of the single output of
+// the graph with respect to its inputs at inputValues, as the Jacobian of
+// its GradGraph.
+func (g *Graph) Hessian(inputValues []float64) (hess [][]float64, err error) {
+	if len(g.Outputs) != 1 {
+		err = fmt.Errorf("error hessian needs a graph with a single output, got %d", len(g.Outputs))
+		return
+	}
+	if len(inputValues) != len(g.Inputs) {
+		err = fmt.Errorf("error list of values must match list of inputs")
+		return
+	}
+	d, err := g.GradGraph("hessian")
+	if err != nil {
+		return
+	}
+	jac, err := d.Jacobian(append(append([]float64{}, inputValues...), 1))
+	if err != nil {
+		return
+	}
+	// drop the column of the upstream gradient
+	hess = make([][]float64, len(jac))
+	for i, row := range jac {
+		hess[i] = row[:len(g.Inputs)]
+	}
+	return
+}
+
+func newMatrix(rows, cols int) [][]float64 {
+	m := make([][]float64, rows)
+	for i := range m {
+		m[i] = make([]float64, cols)
+	}
+	return m
+}
@@ -0,0 +1,75 @@
+package nngo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertMatrix(t *testing.T, want, got [][]float64, delta float64) {
+	assert.Len(t, got, len(want))
+	for i := range want {
+		assert.InDeltaSlice(t, want[i], got[i], delta)
+	}
+}
+
+// softmax has as many inputs as outputs, J = diag(p) - p pᵀ
+func TestJacobian1(t *testing.T) {
+	s := SoftMax(3, "s")
+	jac, err := s.Jacobian([]float64{1, 2, 3})
+	Panic(err)
+	expSum := math.Exp(1) + math.Exp(2) + math.Exp(3)
+	p := []float64{math.Exp(1) / expSum, math.Exp(2) / expSum, math.Exp(3) / expSum}
+	want := newMatrix(3, 3)
+	for i := range want {
+		for j := range want[i] {
+			want[i][j] = -p[i] * p[j]
+		}
+		want[i][i] += p[i]
+	}
+	assertMatrix(t, want, jac, 1e-12)
+
+	reverse, err := s.jacobianReverse([]float64{1, 2, 3})
+	Panic(err)
+	assertMatrix(t, want, reverse, 1e-12)
+	assert.Equal(t, 0.0, s.Inputs[0].Grad)
+}
+
+// a linear layer has more inputs, its weights among them, than outputs
+func TestJacobian2(t *testing.T) {
+	linear := NewLinearInit(2, 2, "l", rand.New(rand.NewSource(42)))
+	inputs := []float64{1, 2, 3, 4, 5, 6, 7, 8}
+	jac, err := linear.Graph.Jacobian(inputs)
+	Panic(err)
+	want := [][]float64{
+		{3, 4, 1, 2, 1, 0, 0, 0},
+		{6, 7, 0, 0, 0, 1, 2, 1},
+	}
+	assertMatrix(t, want, jac, 0)
+
+	forward, err := linear.Graph.jacobianForward(inputs)
+	Panic(err)
+	assertMatrix(t, want, forward, 0)
+
+	_, err = linear.Graph.Jacobian([]float64{1})
+	assert.Error(t, err)
+}
+
+// f(x, y) = x² y + exp(x y)
+func TestHessian(t *testing.T) {
+	b := NewBuilder("f")
+	x, y := b.Input("x"), b.Input("y")
+	g := b.Build(b.Add(b.Mul(x, x, y), b.Exp(b.Mul(x, y))))
+	hess, err := g.Hessian([]float64{1, 2})
+	Panic(err)
+	e := math.Exp(2)
+	assertMatrix(t, [][]float64{{4 + 4*e, 2 + 3*e}, {2 + 3*e, e}}, hess, 1e-9)
+
+	s := SoftMax(2, "s")
+	_, err = s.Hessian([]float64{1, 2})
+	assert.Error(t, err)
+	_, err = g.Hessian([]float64{1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package nngo
+
+import "fmt"
+
+// The loss graphs below take n predictions followed by n targets, labelled
+// "<label>-prediction-i" and "<label>-target-i", and have a single output,
+// the loss, so Backprop can start from it with a gradient of 1.
+
+// MSELoss is the mean of (prediction - target)².
+func MSELoss(n int, label string) Graph {
+	return lossGraph(n, label, 1/float64(n), func(b *Builder, p, y *Node) *Node {
+		return b.Pow(difference(b, p, y), 2)
+	})
+}
+
+// MAELoss is the mean of |prediction - target|.
+func MAELoss(n int, label string) Graph {
+	return lossGraph(n, label, 1/float64(n), func(b *Builder, p, y *Node) *Node {
+		return b.Abs(difference(b, p, y))
+	})
+}
+
+// HuberLoss is the mean of the Huber op applied to prediction - target, which
+// is quadratic up to delta and linear beyond.
+func HuberLoss(n int, delta float64, label string) Graph {
+	return lossGraph(n, label, 1/float64(n), func(b *Builder, p, y *Node) *Node {
+		return b.Huber(difference(b, p, y), delta)
+	})
+}
+
+// BCELoss is the mean binary cross-entropy of predicted probabilities against
+// targets in [0, 1].
+func BCELoss(n int, label string) Graph {
+	return lossGraph(n, label, -1/float64(n), func(b *Builder, p, y *Node) *Node {
+		return b.Add(b.XLogY(y, p), b.XLogY(complement(b, y), complement(b, p)))
+	})
+}
+
+// CrossEntropyLoss is the categorical cross-entropy -Σ target * log(prediction)
+// of a predicted distribution, e.g. the outputs of SoftMax.
+func CrossEntropyLoss(n int, label string) Graph {
+	return lossGraph(n, label, -1, func(b *Builder, p, y *Node) *Node {
+		return b.XLogY(y, p)
+	})
+}
+
+// HingeLoss is the mean of max(0, 1 - target * prediction) for targets of
+// -1 or 1.
+func HingeLoss(n int, label string) Graph {
+	return lossGraph(n, label, 1/float64(n), func(b *Builder, p, y *Node) *Node {
+		return b.Relu(complement(b, b.Mul(y, p)))
+	})
+}
+
+// KLDivLoss is the Kullback-Leibler divergence Σ target * log(target /
+// prediction) of the predicted distribution from the target one.
+func KLDivLoss(n int, label string) Graph {
+	return lossGraph(n, label, 1, func(b *Builder, p, y *Node) *Node {
+		return difference(b, b.XLogY(y, y), b.XLogY(y, p))
+	})
+}
+
+// SoftMaxCrossEntropyLoss is CrossEntropyLoss of the softmax of the
+// predictions, which are logits. A single fused node computes it through
+// log-sum-exp, and its gradient with respect to the logits is simply p - y
+// for targets that sum to 1.
+func SoftMaxCrossEntropyLoss(n int, label string) Graph {
+	b := NewBuilder(label)
+	inputs := make([](*Node), 2*n)
+	for i := 0; i < n; i++ {
+		inputs[i] = b.Input(fmt.Sprintf("%s-prediction-%d", label, i))
+	}
+	for i := 0; i < n; i++ {
+		inputs[n+i] = b.Input(fmt.Sprintf("%s-target-%d", label, i))
+	}
+	return b.Build(b.Op(SoftMaxCrossEntropy, 0, inputs...))
+}
+
+// lossGraph sums term over the predictions and targets and scales the sum.
+func lossGraph(n int, label string, scale float64, term func(b *Builder, p, y *Node) *Node) Graph {
+	b := NewBuilder(label)
+	predictions := make([](*Node), n)
+	targets := make([](*Node), n)
+	for i := range predictions {
+		predictions[i] = b.Input(fmt.Sprintf("%s-prediction-%d", label, i))
+	}
+	for i := range targets {
+		targets[i] = b.Input(fmt.Sprintf("%s-target-%d", label, i))
+	}
+	terms := make([](*Node), n)
+	for i := range terms {
+		terms[i] = term(b, predictions[i], targets[i])
+	}
+	return b.Build(b.Mul(b.Const(scale), b.Add(terms...)))
+}
+
+func difference(b *Builder, x, y *Node) *Node {
+	return b.Add(x, b.Mul(b.Const(-1), y))
+}
+
+func complement(b *Builder, x *Node) *Node {
+	return difference(b, b.Const(1), x)
+}
+
+// WithLoss feeds the outputs of m into the predictions of loss. The data
+// inputs of the result are those of m followed by the targets, and its only
+// output is the loss.
+func WithLoss(m Module, loss Graph) (l Module, err error) {
+	outputs := m.Graph.Outputs
+	if len(loss.Inputs) != 2*len(outputs) {
+		err = fmt.Errorf("error loss takes %d inputs but module has %d outputs", len(loss.Inputs), len(outputs))
+		return
+	}
+	wires := make([]Wire, len(outputs))
+	for i, out := range outputs {
+		wires[i] = Wire{From: out.Label, To: loss.Inputs[i].Label}
+	}
+	g, err := Compose(m.Graph, loss, wires...)
+	if err != nil {
+		return
+	}
+
+	inputs := append([](*Node){}, m.dataInputs()...)
+	Append(&inputs, loss.Inputs[len(outputs):]...)
+	Append(&inputs, m.Params...)
+	g.Inputs = inputs
+	l = Module{Graph: g, Params: m.Params, parameters: m.parameters}
+	return
+}
@@ -0,0 +1,81 @@
+package nngo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoss1(t *testing.T) {
+	cases := []struct {
+		name   string
+		loss   Graph
+		inputs []float64
+		want   float64
+	}{
+		{"mse", MSELoss(3, "mse"), []float64{1, 2, 3, 0.5, 2, 5}, (0.25 + 0 + 4) / 3},
+		{"mae", MAELoss(3, "mae"), []float64{1, 2, 3, 0.5, 2.5, 5}, (0.5 + 0.5 + 2) / 3},
+		{"huber", HuberLoss(2, 1, "huber"), []float64{1, 3, 0.5, 0}, (0.125 + 2.5) / 2},
+		{"bce", BCELoss(2, "bce"), []float64{0.8, 0.3, 1, 0.5}, -(math.Log(0.8) + 0.5*math.Log(0.3) + 0.5*math.Log(0.7)) / 2},
+		{"cross-entropy", CrossEntropyLoss(3, "ce"), []float64{0.2, 0.5, 0.3, 0, 1, 0}, -math.Log(0.5)},
+		{"hinge", HingeLoss(3, "hinge"), []float64{0.5, -2, 0.3, 1, -1, -1}, (0.5 + 0 + 1.3) / 3},
+		{"kl", KLDivLoss(2, "kl"), []float64{0.4, 0.6, 0.5, 0.5}, 0.5*math.Log(0.5/0.4) + 0.5*math.Log(0.5/0.6)},
+	}
+	for _, c := range cases {
+		g := c.loss
+		assert.Len(t, g.Outputs, 1, c.name)
+		assert.NoError(t, g.Validate(), c.name)
+
+		Panic(g.Forward(c.inputs))
+		assert.InDelta(t, c.want, g.Outputs[0].Val, 1e-12, c.name)
+
+		mismatches, err := GradCheck(&g, c.inputs, []float64{1}, 1e-6, 1e-6)
+		Panic(err)
+		assert.Empty(t, mismatches, c.name)
+	}
+}
+
+// zero targets ignore the predictions they match, even at 0
+func TestLoss2(t *testing.T) {
+	g := CrossEntropyLoss(2, "ce")
+	Panic(g.Forward([]float64{0, 1, 0, 1}))
+	assert.Equal(t, 0.0, g.Outputs[0].Val)
+
+	g = KLDivLoss(2, "kl")
+	Panic(g.Forward([]float64{0.5, 0.5, 0, 1}))
+	assert.InDelta(t, math.Log(2), g.Outputs[0].Val, 1e-12)
+
+	g = BCELoss(1, "bce")
+	assert.ErrorIs(t, g.Forward([]float64{0, 1}), ErrDomain)
+}
+
+// fits the line of TestBackProp3 with the loss in the graph
+func TestLoss3(t *testing.T) {
+	linear := NewLinearInit(2, 1, "l", rand.New(rand.NewSource(42)))
+	m, err := WithLoss(linear, MSELoss(1, "mse"))
+	Panic(err)
+	assert.Equal(t, "mse-target-0", m.Graph.Inputs[2].Label)
+	assert.Len(t, m.Params, 3)
+
+	optimizer := NewSGD(1e-2, 0.9, false)
+	var losses []float64
+	for i := 0; i < 100; i++ {
+		loss := 0.
+		for _, point := range [][]float64{{0, 2, 0}, {3, 0, 0}} {
+			Panic(m.Forward(point))
+			loss += m.Graph.Outputs[0].Val
+			m.Graph.ZeroGrad()
+			Panic(m.Backprop([]float64{1}, &optimizer))
+		}
+		Append(&losses, loss)
+	}
+	p := m.Weights()
+	assert.Less(t, losses[len(losses)-1], 1e-2*losses[0])
+	assert.InDelta(t, -1./3., p[0]/p[2], 5e-2)
+	assert.InDelta(t, -1./2., p[1]/p[2], 5e-2)
+
+	_, err = WithLoss(NewLinearInit(2, 2, "l2", rand.New(rand.NewSource(42))), MSELoss(1, "mse"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package nngo
+
+import (
+	"fmt"
+	"sync"
+)
+
+const MatMulOp Op = "matmul"
+
+// matMulTile is the edge of the square blocks the kernel works on, sized so
+// that a block of each operand stays in cache.
+const matMulTile = 64
+
+// MatMul multiplies the matrices a (m x k) and b (k x n). With workers > 1
+// blocks of rows of the result are computed on that many goroutines.
+func MatMul(a, b *Tensor, workers int) (c *Tensor, err error) {
+	if a.Dims() != 2 || b.Dims() != 2 || a.Shape[1] != b.Shape[0] {
+		err = fmt.Errorf("error cannot multiply matrices of shapes %v and %v", a.Shape, b.Shape)
+		return
+	}
+	m, k, n := a.Shape[0], a.Shape[1], b.Shape[1]
+	c = Zeros(m, n)
+	ad, bd := a.Contiguous().Data, b.Contiguous().Data
+
+	blocks := (m + matMulTile - 1) / matMulTile
+	if workers <= 1 || blocks <= 1 {
+		matMulRows(c.Data, ad, bd, k, n, 0, m)
+		return
+	}
+
+	rows := make(chan int, blocks)
+	for i := 0; i < m; i += matMulTile {
+		rows <- i
+	}
+	close(rows)
+	var wg sync.WaitGroup
+	for w := 0; w < Min(workers, blocks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range rows {
+				matMulRows(c.Data, ad, bd, k, n, i, Min(i+matMulTile, m))
+			}
+		}()
+	}
+	wg.Wait()
+	return
+}
+
+// matMulRows adds rows [lo, hi) of a*b into c, for row-major a (m x k),
+// b (k x n) and c (m x n), walking k and n in tiles.
+func matMulRows(c, a, b []float64, k, n, lo, hi int) {
+	for p0 := 0; p0 < k; p0 += matMulTile {
+		p1 := Min(p0+matMulTile, k)
+		for j0 := 0; j0 < n; j0 += matMulTile {
+			j1 := Min(j0+matMulTile, n)
+			for i := lo; i < hi; i++ {
+				ci := c[i*n+j0 : i*n+j1]
+				for p := p0; p < p1; p++ {
+					aip := a[i*k+p]
+					if aip == 0 {
+						continue
+					}
+					bp := b[p*n+j0 : p*n+j1]
+					for j := range ci {
+						ci[j] += aip * bp[j]
+					}
+				}
+			}
+		}
+	}
+}
+
+// TensorMatMul multiplies the matrices a and b, see MatMul. Its backward pass
+// computes dA = dC * Bᵀ and dB = Aᵀ * dC.
+func TensorMatMul(label string, a, b *TensorNode, workers int) *TensorNode {
+	return newTensorNode(label, MatMulOp, tensorKernel{
+		forward: func(inputs []*Tensor) (*Tensor, error) {
+			return MatMul(inputs[0], inputs[1], workers)
+		},
+		backward: func(inputs []*Tensor, _, grad *Tensor) (grads []*Tensor, err error) {
+			bt, err := inputs[1].Transpose()
+			if err != nil {
+				return
+			}
+			at, err := inputs[0].Transpose()
+			if err != nil {
+				return
+			}
+			da, err := MatMul(grad, bt, workers)
+			if err != nil {
+				return
+			}
+			db, err := MatMul(at, grad, workers)
+			grads = []*Tensor{da, db}
+			return
+		},
+	}, a, b)
+}
+
+// TensorModule is a layer over tensor graphs. Its parameters are leaf nodes
+// outside of Graph.Inputs that hold their own values, so Forward only takes
+// the data.
+type TensorModule struct {
+	Graph  TensorGraph
+	Params [](*TensorNode)
+}
+
+// NewDense builds y = x * W + b for a batch x of shape (batch, n1), with
+// W of shape (n1, n2) and b of shape (n2) broadcast over the batch. The
+// parameters start at zero.
+func NewDense(n1, n2, batch int, label string, workers int) TensorModule {
+	x := TensorInput(fmt.Sprintf("%s-input", label), batch, n1)
+	w := TensorInput(fmt.Sprintf("%s-weight", label), n1, n2)
+	b := TensorInput(fmt.Sprintf("%s-bias", label), n2)
+	xw := TensorMatMul(fmt.Sprintf("%s-matmul", label), x, w, workers)
+	y := TensorElementwise(fmt.Sprintf("%s-add", label), Add, 0, xw, b)
+	return TensorModule{
+		Graph:  NewTensorGraph([](*TensorNode){x}, [](*TensorNode){y}),
+		Params: [](*TensorNode){w, b},
+	}
+}
+
+func (m *TensorModule) ZeroGrad() {
+	m.Graph.ZeroGrad()
+	for _, p := range m.Params {
+		p.Grad = nil
+	}
+}
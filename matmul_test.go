@@ -0,0 +1,143 @@
+package nngo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func randomTensor(r *rand.Rand, shape ...int) *Tensor {
+	t := Zeros(shape...)
+	for i := range t.Data {
+		t.Data[i] = RandomFloat64(r, -1, 1)
+	}
+	return t
+}
+
+func naiveMatMul(a, b *Tensor) *Tensor {
+	c := Zeros(a.Shape[0], b.Shape[1])
+	for i := 0; i < a.Shape[0]; i++ {
+		for j := 0; j < b.Shape[1]; j++ {
+			for p := 0; p < a.Shape[1]; p++ {
+				c.Data[i*b.Shape[1]+j] += a.At(i, p) * b.At(p, j)
+			}
+		}
+	}
+	return c
+}
+
+func TestMatMul1(t *testing.T) {
+	a := tensor([]float64{1, 2, 3, 4, 5, 6}, 2, 3)
+	b := tensor([]float64{7, 8, 9, 10, 11, 12}, 3, 2)
+	c, err := MatMul(a, b, 1)
+	Panic(err)
+	assert.Equal(t, []int{2, 2}, c.Shape)
+	assert.Equal(t, []float64{58, 64, 139, 154}, c.Values())
+
+	_, err = MatMul(a, a, 1)
+	assert.Error(t, err)
+
+	// sizes that do not divide into tiles, transposed views and workers
+	r := rand.New(rand.NewSource(42))
+	a = randomTensor(r, 150, 70)
+	b = randomTensor(r, 130, 70)
+	bt, err := b.Transpose()
+	Panic(err)
+	want := naiveMatMul(a, bt)
+	for _, workers := range []int{1, 4} {
+		c, err = MatMul(a, bt, workers)
+		Panic(err)
+		assert.InDeltaSlice(t, want.Values(), c.Values(), 1e-9)
+	}
+}
+
+// f(A, B) = sum(A * B)
+func TestMatMul2(t *testing.T) {
+	a := TensorInput("a", 2, 3)
+	b := TensorInput("b", 3, 2)
+	c := TensorMatMul("c", a, b, 1)
+	f := TensorSum("f", TensorSum("rows", c, 0), 0)
+	graph := NewTensorGraph([](*TensorNode){a, b}, [](*TensorNode){f})
+
+	err := graph.Forward([]*Tensor{
+		tensor([]float64{1, 2, 3, 4, 5, 6}, 2, 3),
+		tensor([]float64{7, 8, 9, 10, 11, 12}, 3, 2),
+	})
+	Panic(err)
+	assert.Equal(t, 58.0+64+139+154, f.Val.At())
+
+	err = graph.Backprop([]*Tensor{Full(1)})
+	Panic(err)
+	// dA = 1 * Bᵀ, dB = Aᵀ * 1
+	assert.Equal(t, []float64{15, 19, 23, 15, 19, 23}, a.Grad.Values())
+	assert.Equal(t, []float64{5, 5, 7, 7, 9, 9}, b.Grad.Values())
+}
+
+// NewDense computes the same layer as NewLinear
+func TestMatMul3(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	n1, n2 := 3, 2
+	linear := NewLinearInit(n1, n2, "l", r)
+	dense := NewDense(n1, n2, 1, "d", 1)
+	w, b := dense.Params[0], dense.Params[1]
+
+	x := []float64{0.5, -1, 2}
+	inputs := append([]float64{}, x...)
+	for i := 0; i < n2; i++ {
+		for j := 0; j < n1; j++ {
+			v := RandomFloat64(r, -1, 1)
+			w.Val.Set(v, j, i)
+			Append(&inputs, v)
+		}
+		b.Val.Data[i] = RandomFloat64(r, -1, 1)
+		Append(&inputs, b.Val.Data[i])
+	}
+
+	Panic(linear.Graph.Forward(inputs))
+	Panic(dense.Graph.Forward([]*Tensor{tensor(x, 1, n1)}))
+	Panic(linear.Graph.Backprop([]float64{1, -2}))
+	Panic(dense.Graph.Backprop([]*Tensor{tensor([]float64{1, -2}, 1, n2)}))
+
+	y := dense.Graph.Outputs[0].Val
+	for i := 0; i < n2; i++ {
+		assert.InDelta(t, linear.Graph.Outputs[i].Val, y.At(0, i), 1e-12)
+		for j := 0; j < n1; j++ {
+			assert.InDelta(t, linear.Params[i*(n1+1)+j].Grad, w.Grad.At(j, i), 1e-12)
+		}
+		assert.InDelta(t, linear.Params[i*(n1+1)+n1].Grad, b.Grad.At(i), 1e-12)
+	}
+	for j := 0; j < n1; j++ {
+		assert.InDelta(t, linear.Graph.Inputs[j].Grad, dense.Graph.Inputs[0].Grad.At(0, j), 1e-12)
+	}
+
+	dense.ZeroGrad()
+	assert.Nil(t, w.Grad)
+}
+
+func benchmarkMatMul(b *testing.B, workers int) {
+	r := rand.New(rand.NewSource(42))
+	x, y := randomTensor(r, 256, 256), randomTensor(r, 256, 256)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := MatMul(x, y, workers)
+		Panic(err)
+	}
+}
+
+func BenchmarkMatMul(b *testing.B) {
+	benchmarkMatMul(b, 1)
+}
+
+func BenchmarkMatMulParallel(b *testing.B) {
+	benchmarkMatMul(b, 4)
+}
+
+func BenchmarkNaiveMatMul(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	x, y := randomTensor(r, 256, 256), randomTensor(r, 256, 256)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		naiveMatMul(x, y)
+	}
+}
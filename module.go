@@ -0,0 +1,299 @@
+package nngo
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Module is a graph together with the parameters it owns. The inputs of
+// Graph are the data inputs followed by Params, whose values are kept on the
+// parameter nodes and fed back to the graph by Forward.
+type Module struct {
+	Graph      Graph
+	Params     [](*Node)
+	parameters []Parameter
+}
+
+// Parameter is a named group of a module's parameter nodes, like the weights
+// or the biases of a layer, listed in row-major order of Shape.
+type Parameter struct {
+	Name  string
+	Shape []int
+	Nodes [](*Node)
+	// Init is used by Module.InitWeights.
+	Init Initializer
+}
+
+func (p Parameter) Values() []float64 {
+	return Map(p.Nodes, func(n *Node) float64 {
+		return n.Val
+	})
+}
+
+func (p Parameter) SetValues(vals []float64) {
+	for i, n := range p.Nodes {
+		n.Val = vals[i]
+	}
+}
+
+// Parameters returns the parameter groups of the module. The groups share
+// their nodes with Params, so changes to them, including to Init, apply to
+// the module.
+func (m *Module) Parameters() []Parameter {
+	return m.parameters
+}
+
+// Weights returns the values of Params.
+func (m *Module) Weights() []float64 {
+	return Map(m.Params, func(n *Node) float64 {
+		return n.Val
+	})
+}
+
+func (m *Module) SetWeights(weights []float64) (err error) {
+	if len(weights) != len(m.Params) {
+		err = fmt.Errorf("error list of weights must match list of params")
+		return
+	}
+	for i, w := range weights {
+		m.Params[i].Val = w
+	}
+	return
+}
+
+// InitWeights fills every parameter group from its initializer.
+func (m *Module) InitWeights(randSource *rand.Rand) {
+	for _, p := range m.parameters {
+		vals := make([]float64, len(p.Nodes))
+		p.Init.Init(vals, p.Shape, randSource)
+		p.SetValues(vals)
+	}
+}
+
+func (m *Module) Forward(inputValues []float64) (err error) {
+	inputs := append([]float64{}, inputValues...)
+	Append(&inputs, m.Weights()...)
+	err = m.Graph.Forward(inputs)
+	return
+}
+
+// Backprop computes the gradients of the module and, unless optimizer is
+// nil, lets it update the parameters.
+func (m *Module) Backprop(upstreamGrads []float64, optimizer Optimizer) (err error) {
+	_, err = m.BackpropClipped(upstreamGrads, optimizer, nil)
+	return
+}
+
+// BackpropClipped is Backprop with the parameter gradients clipped before the
+// optimizer step. It returns the global norm of the gradients before
+// clipping. A nil clipping leaves the gradients as they are.
+func (m *Module) BackpropClipped(upstreamGrads []float64, optimizer Optimizer, clipping *GradientClipping) (norm float64, err error) {
+	err = m.Graph.Backprop(upstreamGrads)
+	if err != nil {
+		return
+	}
+	norm = GlobalNorm(nodeGrads(m.Params))
+	if clipping != nil {
+		err = clipping.Apply(m)
+		if err != nil {
+			return
+		}
+	}
+	if optimizer != nil {
+		optimizer.Step(m.Params)
+	}
+	return
+}
+
+// NewLinear builds a layer of n2 outputs, each the dot product of the n1
+// inputs with its own weights, plus a bias. NewDense computes the same layer
+// over a batch with one MatMul. The parameters are initialized from a
+// randomly seeded source, see NewLinearInit to choose it.
+func NewLinear(n1 int, n2 int, label string) Module {
+	return NewLinearInit(n1, n2, label, nil)
+}
+
+// NewLinearInit is NewLinear with the parameters initialized from
+// randSource, see InitWeights. A nil randSource is randomly seeded.
+func NewLinearInit(n1 int, n2 int, label string, randSource *rand.Rand) Module {
+	inputs := make([](*Node), n1)
+	weights := make([](*Node), n1*n2)
+	biases := make([](*Node), n2)
+	dots := make([](Node), n2)
+	dotPts := ToPtrs(dots)
+	outputs := make([](*Node), n2)
+
+	// initialize inputs
+	for i := 0; i < n1; i++ {
+		node := InputSymbol(fmt.Sprintf("%s-input-%d", label, i), dotPts)
+		inputs[i] = &node
+	}
+	unitNode := InputSymbol("unit", dotPts)
+	unitNode.Val = 1
+
+	// initialize weights
+	for i := 0; i < n2; i++ {
+		for j := 0; j < n1; j++ {
+			num := j + i*n1
+			node := InputSymbol(fmt.Sprintf("%s-weight-%d", label, num), [](*Node){&dots[i]})
+			weights[num] = &node
+		}
+	}
+
+	// initialize biases
+	for i := 0; i < n2; i++ {
+		node := InputSymbol(fmt.Sprintf("%s-bias-%d", label, i), [](*Node){&dots[i]})
+		biases[i] = &node
+	}
+
+	// initalize outputs
+	for i := 0; i < n2; i++ {
+		node := OutputSymbol(fmt.Sprintf("%s-output-%d", label, i), &dots[i])
+		outputs[i] = &node
+	}
+
+	// initialize dots
+	for i := 0; i < n2; i++ {
+		dotInputs := append(inputs, &unitNode)
+		Append(&dotInputs, weights[(n1*i):(n1+n1*i)]...)
+		Append(&dotInputs, biases[i])
+
+		dots[i] = DotNode(
+			fmt.Sprintf("%s-dot-%d", label, i),
+			[](*Node){outputs[i]},
+			dotInputs,
+		)
+	}
+
+	inps := inputs
+	for i := 0; i < n2; i++ {
+		Append(&inps, weights[(n1*i):(n1+n1*i)]...)
+		Append(&inps, biases[i])
+	}
+
+	m := Module{
+		Graph:  NewGraph(inps, outputs, dotPts),
+		Params: inps[n1:],
+		parameters: []Parameter{
+			{Name: fmt.Sprintf("%s-weight", label), Shape: []int{n2, n1}, Nodes: weights, Init: XavierUniform{}},
+			{Name: fmt.Sprintf("%s-bias", label), Shape: []int{n2}, Nodes: biases, Init: ZeroInit},
+		},
+	}
+	m.InitWeights(randomSource(randSource))
+	return m
+}
+
+// randomSource returns randSource, or a randomly seeded source if it is nil.
+func randomSource(randSource *rand.Rand) *rand.Rand {
+	if randSource == nil {
+		randSource = rand.New(rand.NewSource(rand.Int63()))
+	}
+	return randSource
+}
+
+// NewModule wraps a graph without parameters, like SoftMax, so it can be used
+// as a layer of a Sequential.
+func NewModule(graph Graph) Module {
+	return Module{Graph: graph}
+}
+
+// NewActivation builds a layer applying op with the given param to each of n
+// inputs on its own.
+func NewActivation(n int, op Op, param float64, label string) Module {
+	b := NewBuilder(label)
+	outputs := make([](*Node), n)
+	for i := range outputs {
+		outputs[i] = b.Op(op, param, b.Input(fmt.Sprintf("%s-input-%d", label, i)))
+	}
+	return NewModule(b.Build(outputs...))
+}
+
+func (m *Module) dataInputs() [](*Node) {
+	return m.Graph.Inputs[:len(m.Graph.Inputs)-len(m.Params)]
+}
+
+// Sequential is a module feeding the outputs of each layer into the data
+// inputs of the next. Its parameters are those of the layers, in order.
+type Sequential struct {
+	Module
+	Layers []Module
+}
+
+// NewSequential links the layers together, so they should not be evaluated
+// on their own afterwards. A Sequential can itself be a layer through its
+// Module.
+func NewSequential(layers ...Module) (s Sequential, err error) {
+	if len(layers) == 0 {
+		err = fmt.Errorf("error sequential needs at least one layer")
+		return
+	}
+	for i := 1; i < len(layers); i++ {
+		outputs, inputs := len(layers[i-1].Graph.Outputs), len(layers[i].dataInputs())
+		if outputs != inputs {
+			err = fmt.Errorf("error layer %d has %d outputs but layer %d takes %d inputs", i-1, outputs, i, inputs)
+			return
+		}
+	}
+
+	inputs := append([](*Node){}, layers[0].dataInputs()...)
+	var intermediates, params [](*Node)
+	var parameters []Parameter
+	for i := range layers {
+		layer := &layers[i]
+		if i > 0 {
+			prev := layers[i-1].Graph.Outputs
+			for j, inp := range layer.dataInputs() {
+				// layers of an earlier Sequential are already linked
+				if !contains(inp.Inputs, prev[j]) {
+					Append(&prev[j].Outputs, inp)
+					Append(&inp.Inputs, prev[j])
+				}
+			}
+			Append(&intermediates, prev...)
+			Append(&intermediates, layer.dataInputs()...)
+		}
+		Append(&intermediates, layer.Graph.Intermediates...)
+		Append(&params, layer.Params...)
+		Append(&parameters, layer.parameters...)
+	}
+	Append(&inputs, params...)
+	graph := NewGraph(inputs, layers[len(layers)-1].Graph.Outputs, intermediates)
+	for i := range layers {
+		Append(&graph.Subgraphs, layers[i].Graph.subgraphs()...)
+	}
+
+	s = Sequential{
+		Module: Module{
+			Graph:      graph,
+			Params:     params,
+			parameters: parameters,
+		},
+		Layers: layers,
+	}
+	return
+}
+
+// NewMLP builds a multi-layer perceptron with sizes[0] inputs and
+// sizes[len(sizes)-1] outputs, applying activation with the given param,
+// like the slope of LeakyRelu, after every linear layer but the last. The linear layers are initialized from a
+// randomly seeded source, see NewMLPInit to choose it. It panics with fewer
+// than two sizes.
+func NewMLP(sizes []int, activation Op, param float64, label string) Sequential {
+	return NewMLPInit(sizes, activation, param, label, nil)
+}
+
+// NewMLPInit is NewMLP with the linear layers initialized from randSource in
+// order. A nil randSource is randomly seeded.
+func NewMLPInit(sizes []int, activation Op, param float64, label string, randSource *rand.Rand) Sequential {
+	randSource = randomSource(randSource)
+	var layers []Module
+	for i := 1; i < len(sizes); i++ {
+		Append(&layers, NewLinearInit(sizes[i-1], sizes[i], fmt.Sprintf("%s-linear-%d", label, i-1), randSource))
+		if i < len(sizes)-1 {
+			Append(&layers, NewActivation(sizes[i], activation, param, fmt.Sprintf("%s-%s-%d", label, activation, i-1)))
+		}
+	}
+	s, err := NewSequential(layers...)
+	Panic(err)
+	return s
+}
@@ -0,0 +1,102 @@
+package nngo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// relu(x * w1 + b1) * w2 + b2 with one hidden unit, against the same layers
+// evaluated by hand
+func TestSequential1(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	s, err := NewSequential(NewLinearInit(2, 1, "l1", r), NewActivation(1, Relu, 0, "a"), NewLinearInit(1, 1, "l2", r))
+	Panic(err)
+	assert.Len(t, s.Params, 5)
+	assert.Len(t, s.Parameters(), 4)
+	assert.Len(t, s.Graph.Inputs, 7)
+
+	Panic(s.SetWeights([]float64{1, -1, 0.5, 3, 1}))
+	Panic(s.Forward([]float64{2, 1}))
+	assert.Equal(t, 3*1.5+1, s.Graph.Outputs[0].Val)
+
+	s.Graph.ZeroGrad()
+	Panic(s.Backprop([]float64{1}, nil))
+	grads := Map(s.Params, func(n *Node) float64 {
+		return n.Grad
+	})
+	assert.Equal(t, []float64{6, 3, 3, 1.5, 1}, grads)
+
+	Panic(s.Forward([]float64{-2, 1}))
+	assert.Equal(t, 1.0, s.Graph.Outputs[0].Val)
+
+	// composing the same layers again does not link them twice
+	again, err := NewSequential(s.Layers...)
+	Panic(err)
+	Panic(again.Forward([]float64{2, 1}))
+	assert.Equal(t, 3*1.5+1, again.Graph.Outputs[0].Val)
+
+	_, err = NewSequential(NewLinearInit(2, 3, "l1", r), NewLinearInit(2, 1, "l2", r))
+	assert.Error(t, err)
+	_, err = NewSequential()
+	assert.Error(t, err)
+}
+
+// a 2-4-4-2 classifier learns XOR through a softmax
+func TestSequential2(t *testing.T) {
+	mlp := NewMLPInit([]int{2, 4, 4, 2}, Tanh, 0, "mlp", rand.New(rand.NewSource(42)))
+	assert.Len(t, mlp.Layers, 5)
+	assert.Len(t, mlp.Params, 12+20+10)
+
+	s, err := NewSequential(mlp.Module, NewModule(SoftMax(2, "softmax")))
+	Panic(err)
+	optimizer := NewAdam(5e-2)
+
+	points := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	labels := []int{0, 1, 1, 0}
+	var losses []float64
+	for epoch := 0; epoch < 300; epoch++ {
+		loss := 0.
+		for i, point := range points {
+			Panic(s.Forward(point))
+			p := s.Graph.Outputs[labels[i]].Val
+			loss -= math.Log(p)
+			upstream := []float64{0, 0}
+			upstream[labels[i]] = -1 / p
+			s.Graph.ZeroGrad()
+			Panic(s.Backprop(upstream, &optimizer))
+		}
+		Append(&losses, loss)
+	}
+	assert.Less(t, losses[len(losses)-1], 1e-2*losses[0])
+	for i, point := range points {
+		Panic(s.Forward(point))
+		assert.Greater(t, s.Graph.Outputs[labels[i]].Val, 0.9)
+	}
+}
+
+// a fresh MLP starts from random weights, so every parameter gets a gradient
+func TestSequential3(t *testing.T) {
+	mlp := NewMLPInit([]int{2, 3, 1}, Tanh, 0, "mlp", rand.New(rand.NewSource(42)))
+	Panic(mlp.Forward([]float64{1, -1}))
+	mlp.Graph.ZeroGrad()
+	Panic(mlp.Backprop([]float64{1}, nil))
+	for _, n := range mlp.Params {
+		assert.NotZero(t, n.Grad, n.Label)
+	}
+}
+
+// the param of the activation, here the slope of LeakyRelu, reaches every
+// activation layer
+func TestSequential4(t *testing.T) {
+	mlp := NewMLP([]int{1, 1, 1}, LeakyRelu, 0.1, "mlp")
+	assert.Equal(t, 0.1, mlp.Layers[1].Graph.Intermediates[0].Param)
+	Panic(mlp.SetWeights([]float64{1, 0, 1, 0}))
+
+	Panic(mlp.Forward([]float64{-2}))
+	assert.InDelta(t, -0.2, mlp.Graph.Outputs[0].Val, 1e-12)
+	Panic(mlp.Forward([]float64{3}))
+	assert.InDelta(t, 3, mlp.Graph.Outputs[0].Val, 1e-12)
+}
@@ -0,0 +1,422 @@
+package nngo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Operator is a differentiable function of the values feeding a node. Every
+// Op a node can carry is backed by an Operator in the registry, and custom
+// operators can be added from outside the package with RegisterOperator.
+type Operator interface {
+	// Name is the Op the operator is registered under.
+	Name() Op
+	// CheckArity returns an error if the operator cannot take n inputs.
+	CheckArity(n int) error
+	// Forward computes the value of a node from the values of its inputs and
+	// the node's Param.
+	Forward(inputs []float64, param float64) (float64, error)
+	// Backward writes the partial derivative of the output with respect to
+	// inputs[i] into partials[i]. out is the value returned by Forward.
+	Backward(inputs []float64, out, param float64, partials []float64)
+}
+
+var operators = map[Op]Operator{}
+
+// RegisterOperator makes op available to every node whose Op matches
+// op.Name(). It is meant to be called from init functions and is not safe
+// for use concurrently with graph evaluation.
+func RegisterOperator(op Operator) (err error) {
+	name := op.Name()
+	if name == "" {
+		err = fmt.Errorf("error operator name must not be empty")
+		return
+	}
+	if _, ok := operators[name]; ok {
+		err = fmt.Errorf("error operator %q is already registered", name)
+		return
+	}
+	operators[name] = op
+	return
+}
+
+func LookupOperator(op Op) (o Operator, ok bool) {
+	o, ok = operators[op]
+	return
+}
+
+type funcOperator struct {
+	name     Op
+	arity    func(n int) error
+	forward  func(inputs []float64, param float64) (float64, error)
+	backward func(inputs []float64, out, param float64, partials []float64)
+}
+
+func (f funcOperator) Name() Op {
+	return f.name
+}
+
+func (f funcOperator) CheckArity(n int) error {
+	return f.arity(n)
+}
+
+func (f funcOperator) Forward(inputs []float64, param float64) (float64, error) {
+	return f.forward(inputs, param)
+}
+
+func (f funcOperator) Backward(inputs []float64, out, param float64, partials []float64) {
+	f.backward(inputs, out, param, partials)
+}
+
+// NewOperator builds an Operator from plain functions. An arity of -1 accepts
+// any positive number of inputs.
+func NewOperator(
+	name Op,
+	arity int,
+	forward func(inputs []float64, param float64) (float64, error),
+	backward func(inputs []float64, out, param float64, partials []float64),
+) Operator {
+	check := exactly(arity)
+	if arity < 0 {
+		check = atLeast(1)
+	}
+	return funcOperator{name, check, forward, backward}
+}
+
+func exactly(want int) func(n int) error {
+	return func(n int) error {
+		if n != want {
+			return fmt.Errorf("error expected %d inputs, got %d", want, n)
+		}
+		return nil
+	}
+}
+
+func atLeast(want int) func(n int) error {
+	return func(n int) error {
+		if n < want {
+			return fmt.Errorf("error expected at least %d inputs, got %d", want, n)
+		}
+		return nil
+	}
+}
+
+// unary builds a single input operator from its value and its derivative,
+// which may use either the input x or the output y.
+func unary(name Op, forward func(x, param float64) (float64, error), derivative func(x, y, param float64) float64) funcOperator {
+	return funcOperator{
+		name:  name,
+		arity: exactly(1),
+		forward: func(inputs []float64, param float64) (float64, error) {
+			return forward(inputs[0], param)
+		},
+		backward: func(inputs []float64, out, param float64, partials []float64) {
+			partials[0] = derivative(inputs[0], out, param)
+		},
+	}
+}
+
+func pure(f func(x float64) float64) func(x, param float64) (float64, error) {
+	return func(x, _ float64) (float64, error) {
+		return f(x), nil
+	}
+}
+
+func init() {
+	builtins := []Operator{
+		funcOperator{
+			name:  Add,
+			arity: atLeast(1),
+			forward: func(inputs []float64, _ float64) (float64, error) {
+				return Sum(inputs), nil
+			},
+			backward: func(inputs []float64, _, _ float64, partials []float64) {
+				for i := range partials {
+					partials[i] = 1
+				}
+			},
+		},
+		funcOperator{
+			name:  Multiply,
+			arity: atLeast(1),
+			forward: func(inputs []float64, _ float64) (float64, error) {
+				return Product(inputs), nil
+			},
+			backward: func(inputs []float64, _, _ float64, partials []float64) {
+				// the running products from the left and then from the right
+				// give the product of every input but the i-th, which stays
+				// correct when some inputs are zero
+				for i, acc := 0, 1.0; i < len(inputs); i++ {
+					partials[i] = acc
+					acc *= inputs[i]
+				}
+				for i, acc := len(inputs)-1, 1.0; i >= 0; i-- {
+					partials[i] *= acc
+					acc *= inputs[i]
+				}
+			},
+		},
+		funcOperator{
+			name: Dot,
+			arity: func(n int) error {
+				if n == 0 || n%2 != 0 {
+					return fmt.Errorf("error dot expects a positive even number of inputs, got %d", n)
+				}
+				return nil
+			},
+			forward: func(inputs []float64, _ float64) (float64, error) {
+				d := len(inputs) / 2
+				return DotProduct(inputs[:d], inputs[d:]), nil
+			},
+			backward: func(inputs []float64, _, _ float64, partials []float64) {
+				d := len(inputs) / 2
+				for i := range inputs {
+					if i < d {
+						partials[i] = inputs[i+d]
+					} else {
+						partials[i] = inputs[i-d]
+					}
+				}
+			},
+		},
+		funcOperator{
+			name: Pow,
+			arity: func(n int) error {
+				if n != 1 && n != 2 {
+					return fmt.Errorf("error pow expects 1 or 2 inputs, got %d", n)
+				}
+				return nil
+			},
+			forward: func(inputs []float64, param float64) (val float64, err error) {
+				x, exponent := inputs[0], powExponent(inputs, param)
+				if x < 0 && exponent != math.Trunc(exponent) || x == 0 && exponent < 0 {
+					err = fmt.Errorf("%w: pow(%v, %v)", ErrDomain, x, exponent)
+					return
+				}
+				val = math.Pow(x, exponent)
+				return
+			},
+			backward: func(inputs []float64, out, param float64, partials []float64) {
+				x, exponent := inputs[0], powExponent(inputs, param)
+				partials[0] = 0
+				if exponent != 0 {
+					partials[0] = exponent * math.Pow(x, exponent-1)
+				}
+				if len(inputs) == 2 {
+					partials[1] = 0
+					if x > 0 {
+						partials[1] = out * math.Log(x)
+					}
+				}
+			},
+		},
+		unary(Relu, pure(func(x float64) float64 {
+			return Max(0, x)
+		}), func(x, _, _ float64) float64 {
+			return step(x, 1, 0)
+		}),
+		unary(Exp, pure(math.Exp), func(_, y, _ float64) float64 {
+			return y
+		}),
+		unary(Reciprocal, pure(func(x float64) float64 {
+			return 1 / x
+		}), func(_, y, _ float64) float64 {
+			return -y * y
+		}),
+		unary(Log, func(x, _ float64) (float64, error) {
+			if x <= 0 {
+				return 0, fmt.Errorf("%w: log(%v)", ErrDomain, x)
+			}
+			return math.Log(x), nil
+		}, func(x, _, _ float64) float64 {
+			return 1 / x
+		}),
+		unary(Sqrt, func(x, _ float64) (float64, error) {
+			if x < 0 {
+				return 0, fmt.Errorf("%w: sqrt(%v)", ErrDomain, x)
+			}
+			return math.Sqrt(x), nil
+		}, func(_, y, _ float64) float64 {
+			return 0.5 / y
+		}),
+		unary(Tanh, pure(math.Tanh), func(_, y, _ float64) float64 {
+			return 1 - y*y
+		}),
+		unary(Sigmoid, pure(sigmoid), func(_, y, _ float64) float64 {
+			return y * (1 - y)
+		}),
+		unary(Softplus, pure(softplus), func(x, _, _ float64) float64 {
+			return sigmoid(x)
+		}),
+		unary(Gelu, pure(func(x float64) float64 {
+			return x * normalCDF(x)
+		}), func(x, _, _ float64) float64 {
+			return normalCDF(x) + x*normalPDF(x)
+		}),
+		unary(Elu, func(x, alpha float64) (float64, error) {
+			return step(x, x, alpha*math.Expm1(x)), nil
+		}, func(x, y, alpha float64) float64 {
+			return step(x, 1, y+alpha)
+		}),
+		unary(LeakyRelu, func(x, slope float64) (float64, error) {
+			return step(x, x, slope*x), nil
+		}, func(x, _, slope float64) float64 {
+			return step(x, 1, slope)
+		}),
+		unary(Abs, pure(math.Abs), func(x, _, _ float64) float64 {
+			switch {
+			case x > 0:
+				return 1
+			case x < 0:
+				return -1
+			}
+			return 0
+		}),
+		unary(Huber, func(x, delta float64) (float64, error) {
+			if math.Abs(x) <= delta {
+				return 0.5 * x * x, nil
+			}
+			return delta * (math.Abs(x) - 0.5*delta), nil
+		}, func(x, _, delta float64) float64 {
+			return math.Max(-delta, math.Min(delta, x))
+		}),
+		funcOperator{
+			name:  XLogY,
+			arity: exactly(2),
+			forward: func(inputs []float64, _ float64) (val float64, err error) {
+				x, y := inputs[0], inputs[1]
+				if x == 0 {
+					return
+				}
+				if y <= 0 {
+					err = fmt.Errorf("%w: %v * log(%v)", ErrDomain, x, y)
+					return
+				}
+				val = x * math.Log(y)
+				return
+			},
+			backward: func(inputs []float64, _, _ float64, partials []float64) {
+				x, y := inputs[0], inputs[1]
+				partials[0], partials[1] = 0, 0
+				if y > 0 {
+					partials[0] = math.Log(y)
+					partials[1] = x / y
+				}
+			},
+		},
+		funcOperator{
+			name:  Maximum,
+			arity: atLeast(1),
+			forward: func(inputs []float64, _ float64) (float64, error) {
+				return Max(inputs...), nil
+			},
+			backward: func(inputs []float64, out, _ float64, partials []float64) {
+				found := false
+				for i, x := range inputs {
+					partials[i] = 0
+					if x == out && !found {
+						partials[i] = 1
+						found = true
+					}
+				}
+			},
+		},
+		// 1 for x > 0 and param otherwise, the derivative of Relu and
+		// LeakyRelu
+		unary(opHeaviside, func(x, param float64) (float64, error) {
+			return step(x, 1, param), nil
+		}, func(_, _, _ float64) float64 {
+			return 0
+		}),
+		unary(opNormalCDF, pure(normalCDF), func(x, _, _ float64) float64 {
+			return normalPDF(x)
+		}),
+		// 1/x for x > 0 and 0 otherwise, the guarded partials of XLogY
+		unary(opPositiveReciprocal, pure(func(x float64) float64 {
+			if x <= 0 {
+				return 0
+			}
+			return 1 / x
+		}), func(_, y, _ float64) float64 {
+			return -y * y
+		}),
+		// 1 if the input at index param is the first of the largest inputs
+		// and 0 otherwise, the partials of Maximum
+		funcOperator{
+			name:  opIsMax,
+			arity: atLeast(1),
+			forward: func(inputs []float64, param float64) (float64, error) {
+				i := int(param)
+				if i < 0 || i >= len(inputs) {
+					return 0, fmt.Errorf("error is-max index %d out of range for %d inputs", i, len(inputs))
+				}
+				for _, x := range inputs[:i] {
+					if x >= inputs[i] {
+						return 0, nil
+					}
+				}
+				for _, x := range inputs[i+1:] {
+					if x > inputs[i] {
+						return 0, nil
+					}
+				}
+				return 1, nil
+			},
+			backward: func(_ []float64, _, _ float64, partials []float64) {
+				for i := range partials {
+					partials[i] = 0
+				}
+			},
+		},
+		funcOperator{
+			name: SoftMaxCrossEntropy,
+			arity: func(n int) error {
+				if n == 0 || n%2 != 0 {
+					return fmt.Errorf("error softmax-cross-entropy expects a positive even number of inputs, got %d", n)
+				}
+				return nil
+			},
+			forward: func(inputs []float64, _ float64) (float64, error) {
+				d := len(inputs) / 2
+				logits, targets := inputs[:d], inputs[d:]
+				lse := logSumExp(logits)
+				loss := 0.
+				for i := range logits {
+					loss += targets[i] * (lse - logits[i])
+				}
+				return loss, nil
+			},
+			backward: func(inputs []float64, _, _ float64, partials []float64) {
+				// p - y for targets summing to 1
+				d := len(inputs) / 2
+				logits, targets := inputs[:d], inputs[d:]
+				lse := logSumExp(logits)
+				total := Sum(targets)
+				for i := range logits {
+					partials[i] = math.Exp(logits[i]-lse)*total - targets[i]
+					partials[d+i] = lse - logits[i]
+				}
+			},
+		},
+	}
+	for _, op := range builtins {
+		Panic(RegisterOperator(op))
+	}
+}
+
+// powExponent returns the exponent of a Pow node, which is either its second
+// input or, for a single input, the constant stored in its Param.
+func powExponent(inputs []float64, param float64) float64 {
+	if len(inputs) == 2 {
+		return inputs[1]
+	}
+	return param
+}
+
+// step returns positive for x > 0 and otherwise.
+func step(x, positive, otherwise float64) float64 {
+	if x > 0 {
+		return positive
+	}
+	return otherwise
+}
@@ -0,0 +1,77 @@
+package nngo_test
+
+import (
+	"math"
+	"testing"
+
+	"nngo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const clip nngo.Op = "test-clip"
+
+func init() {
+	// clamps its input to [-param, param]
+	nngo.Panic(nngo.RegisterOperator(nngo.NewOperator(
+		clip,
+		1,
+		func(inputs []float64, param float64) (float64, error) {
+			return math.Max(-param, math.Min(param, inputs[0])), nil
+		},
+		func(inputs []float64, _, param float64, partials []float64) {
+			partials[0] = 0
+			if math.Abs(inputs[0]) < param {
+				partials[0] = 1
+			}
+		},
+	)))
+}
+
+// f(x, y) = clip(x * y, 1)
+func TestCustomOperator(t *testing.T) {
+	var a, b nngo.Node
+	x := nngo.InputSymbol("x", [](*nngo.Node){&a})
+	y := nngo.InputSymbol("y", [](*nngo.Node){&a})
+	f := nngo.OutputSymbol("f", &b)
+	a = nngo.MultiplyNode("a", [](*nngo.Node){&b}, [](*nngo.Node){&x, &y})
+	b = nngo.Node{Label: "b", Op: clip, Inputs: [](*nngo.Node){&a}, Outputs: [](*nngo.Node){&f}, Param: 1}
+
+	graph := nngo.NewGraph([](*nngo.Node){&x, &y}, [](*nngo.Node){&f}, [](*nngo.Node){&a, &b})
+
+	err := graph.Forward([]float64{0.5, 0.4})
+	nngo.Panic(err)
+	assert.Equal(t, 0.2, f.Val)
+	graph.Backprop([]float64{1})
+	assert.Equal(t, 0.4, x.Grad)
+	assert.Equal(t, 0.5, y.Grad)
+
+	graph.ZeroGrad()
+	err = graph.Forward([]float64{3, 4})
+	nngo.Panic(err)
+	assert.Equal(t, 1.0, f.Val)
+	graph.Backprop([]float64{1})
+	assert.Equal(t, 0.0, x.Grad)
+	assert.Equal(t, 0.0, y.Grad)
+}
+
+func TestRegisterOperator(t *testing.T) {
+	op, ok := nngo.LookupOperator(nngo.Exp)
+	assert.True(t, ok)
+	assert.Equal(t, nngo.Exp, op.Name())
+	assert.Error(t, nngo.RegisterOperator(op))
+
+	_, ok = nngo.LookupOperator("missing")
+	assert.False(t, ok)
+
+	var a nngo.Node
+	x := nngo.InputSymbol("x", [](*nngo.Node){&a})
+	f := nngo.OutputSymbol("f", &a)
+	a = nngo.Node{Label: "a", Op: "missing", Inputs: [](*nngo.Node){&x}, Outputs: [](*nngo.Node){&f}}
+	graph := nngo.NewGraph([](*nngo.Node){&x}, [](*nngo.Node){&f}, [](*nngo.Node){&a})
+	assert.Error(t, graph.Forward([]float64{1}))
+	assert.Error(t, graph.Backprop([]float64{1}))
+
+	a.Op = nngo.Dot
+	assert.Error(t, graph.Forward([]float64{1}))
+}
@@ -0,0 +1,174 @@
+package nngo
+
+import "math"
+
+// Optimizer updates the values of parameter nodes from their gradients.
+// Any state it keeps is per node, so one optimizer can drive the parameters
+// of several modules. The state is created on the first Step, so zero values
+// like &SGD{} work as well as the constructors.
+type Optimizer interface {
+	Step(params [](*Node))
+	GetLearningRate() float64
+	SetLearningRate(learningRate float64)
+}
+
+type baseOptimizer struct {
+	LearningRate float64
+}
+
+func (o *baseOptimizer) GetLearningRate() float64 {
+	return o.LearningRate
+}
+
+func (o *baseOptimizer) SetLearningRate(learningRate float64) {
+	o.LearningRate = learningRate
+}
+
+// SGD is stochastic gradient descent, optionally with classical or Nesterov
+// momentum.
+type SGD struct {
+	baseOptimizer
+	Momentum float64
+	Nesterov bool
+	velocity map[*Node]float64
+}
+
+// NewOptimizer returns plain gradient descent, without momentum.
+func NewOptimizer(learningRate float64) SGD {
+	return NewSGD(learningRate, 0, false)
+}
+
+func NewSGD(learningRate, momentum float64, nesterov bool) SGD {
+	return SGD{
+		baseOptimizer: baseOptimizer{LearningRate: learningRate},
+		Momentum:      momentum,
+		Nesterov:      nesterov,
+	}
+}
+
+func (o *SGD) Step(params [](*Node)) {
+	if o.velocity == nil {
+		o.velocity = map[*Node]float64{}
+	}
+	for _, p := range params {
+		v := o.Momentum*o.velocity[p] + p.Grad
+		o.velocity[p] = v
+		step := v
+		if o.Nesterov {
+			step = p.Grad + o.Momentum*v
+		}
+		p.Val -= o.LearningRate * step
+	}
+}
+
+// Adam keeps bias corrected running averages of the gradients and of their
+// squares.
+type Adam struct {
+	baseOptimizer
+	Beta1   float64
+	Beta2   float64
+	Epsilon float64
+	m       map[*Node]float64
+	v       map[*Node]float64
+	t       map[*Node]int
+}
+
+// NewAdam uses the usual defaults of beta1 = 0.9, beta2 = 0.999 and
+// epsilon = 1e-8.
+func NewAdam(learningRate float64) Adam {
+	return Adam{
+		baseOptimizer: baseOptimizer{LearningRate: learningRate},
+		Beta1:         0.9,
+		Beta2:         0.999,
+		Epsilon:       1e-8,
+	}
+}
+
+func (o *Adam) Step(params [](*Node)) {
+	if o.t == nil {
+		o.m, o.v, o.t = map[*Node]float64{}, map[*Node]float64{}, map[*Node]int{}
+	}
+	for _, p := range params {
+		o.t[p]++
+		m := o.Beta1*o.m[p] + (1-o.Beta1)*p.Grad
+		v := o.Beta2*o.v[p] + (1-o.Beta2)*p.Grad*p.Grad
+		o.m[p], o.v[p] = m, v
+		mHat := m / (1 - math.Pow(o.Beta1, float64(o.t[p])))
+		vHat := v / (1 - math.Pow(o.Beta2, float64(o.t[p])))
+		p.Val -= o.LearningRate * mHat / (math.Sqrt(vHat) + o.Epsilon)
+	}
+}
+
+// AdamW is Adam with weight decay applied directly to the parameters rather
+// than folded into the gradients.
+type AdamW struct {
+	Adam
+	WeightDecay float64
+}
+
+func NewAdamW(learningRate, weightDecay float64) AdamW {
+	return AdamW{
+		Adam:        NewAdam(learningRate),
+		WeightDecay: weightDecay,
+	}
+}
+
+func (o *AdamW) Step(params [](*Node)) {
+	for _, p := range params {
+		p.Val -= o.LearningRate * o.WeightDecay * p.Val
+	}
+	o.Adam.Step(params)
+}
+
+// RMSProp divides each step by a running average of the squared gradients.
+type RMSProp struct {
+	baseOptimizer
+	Decay      float64
+	Epsilon    float64
+	meanSquare map[*Node]float64
+}
+
+// NewRMSProp uses a decay of 0.9 and epsilon = 1e-8.
+func NewRMSProp(learningRate float64) RMSProp {
+	return RMSProp{
+		baseOptimizer: baseOptimizer{LearningRate: learningRate},
+		Decay:         0.9,
+		Epsilon:       1e-8,
+	}
+}
+
+func (o *RMSProp) Step(params [](*Node)) {
+	if o.meanSquare == nil {
+		o.meanSquare = map[*Node]float64{}
+	}
+	for _, p := range params {
+		ms := o.Decay*o.meanSquare[p] + (1-o.Decay)*p.Grad*p.Grad
+		o.meanSquare[p] = ms
+		p.Val -= o.LearningRate * p.Grad / (math.Sqrt(ms) + o.Epsilon)
+	}
+}
+
+// Adagrad divides each step by the root of the sum of all squared gradients
+// seen so far.
+type Adagrad struct {
+	baseOptimizer
+	Epsilon    float64
+	sumSquares map[*Node]float64
+}
+
+func NewAdagrad(learningRate float64) Adagrad {
+	return Adagrad{
+		baseOptimizer: baseOptimizer{LearningRate: learningRate},
+		Epsilon:       1e-8,
+	}
+}
+
+func (o *Adagrad) Step(params [](*Node)) {
+	if o.sumSquares == nil {
+		o.sumSquares = map[*Node]float64{}
+	}
+	for _, p := range params {
+		o.sumSquares[p] += p.Grad * p.Grad
+		p.Val -= o.LearningRate * p.Grad / (math.Sqrt(o.sumSquares[p]) + o.Epsilon)
+	}
+}
@@ -0,0 +1,150 @@
+package nngo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fitLine trains NewLinear(2, 1) on the points (0, 2) and (3, 0) as in
+// TestBackProp3 and returns the loss of every epoch
+func fitLine(linear *Module, optimizer Optimizer, epochs int) (losses []float64) {
+	for i := 0; i < epochs; i++ {
+		loss := 0.
+		for _, point := range [][]float64{{0, 2}, {3, 0}} {
+			Panic(linear.Forward(point))
+			out := linear.Graph.Outputs[0].Val
+			loss += out * out
+			linear.Graph.ZeroGrad()
+			Panic(linear.Backprop([]float64{2 * out}, optimizer))
+		}
+		Append(&losses, loss)
+	}
+	return
+}
+
+// fitTwoLines trains NewLinear(2, 2) on 2x + y = 5 and x - 3y = 3 as in
+// TestBackProp9 and returns the summed loss of every epoch
+func fitTwoLines(linear *Module, optimizer Optimizer, epochs int) (losses []float64) {
+	for i := 0; i < epochs; i++ {
+		loss := 0.
+		for k, points := range [][][]float64{{{0, 5}, {2.5, 0}}, {{0, -1}, {3, 0}}} {
+			for _, point := range points {
+				Panic(linear.Forward(point))
+				out := linear.Graph.Outputs[k].Val
+				loss += out * out
+				upstream := []float64{0, 0}
+				upstream[k] = 2 * out
+				linear.Graph.ZeroGrad()
+				Panic(linear.Backprop(upstream, optimizer))
+			}
+		}
+		Append(&losses, loss)
+	}
+	return
+}
+
+func assertFitsLine(t *testing.T, optimizer Optimizer, epochs int) {
+	linear := NewLinearInit(2, 1, "l", rand.New(rand.NewSource(42)))
+	losses := fitLine(&linear, optimizer, epochs)
+	p := linear.Weights()
+	assert.Less(t, losses[len(losses)-1], 1e-2*losses[0])
+	assert.InDelta(t, -1./3., p[0]/p[2], 5e-2)
+	assert.InDelta(t, -1./2., p[1]/p[2], 5e-2)
+}
+
+func assertFitsTwoLines(t *testing.T, optimizer Optimizer, epochs int) {
+	linear := NewLinearInit(2, 2, "l", rand.New(rand.NewSource(42)))
+	losses := fitTwoLines(&linear, optimizer, epochs)
+	p := linear.Weights()
+	assert.Less(t, losses[len(losses)-1], 1e-2*losses[0])
+	assert.InDelta(t, -2./5., p[0]/p[2], 5e-2)
+	assert.InDelta(t, -1./5., p[1]/p[2], 5e-2)
+	assert.InDelta(t, -1./3., p[3]/p[5], 5e-2)
+	assert.InDelta(t, 1., p[4]/p[5], 5e-2)
+}
+
+func TestMomentum(t *testing.T) {
+	for _, nesterov := range []bool{false, true} {
+		o := NewSGD(1e-2, 0.9, nesterov)
+		assertFitsLine(t, &o, 100)
+		o2 := NewSGD(1e-2, 0.9, nesterov)
+		assertFitsTwoLines(t, &o2, 100)
+	}
+}
+
+func TestAdam(t *testing.T) {
+	o := NewAdam(5e-2)
+	assertFitsLine(t, &o, 300)
+	o2 := NewAdam(5e-2)
+	assertFitsTwoLines(t, &o2, 300)
+}
+
+func TestAdamW(t *testing.T) {
+	o := NewAdamW(5e-2, 1e-3)
+	assertFitsLine(t, &o, 300)
+	o2 := NewAdamW(5e-2, 1e-3)
+	assertFitsTwoLines(t, &o2, 300)
+}
+
+func TestRMSProp(t *testing.T) {
+	o := NewRMSProp(2e-3)
+	assertFitsLine(t, &o, 1000)
+	o2 := NewRMSProp(2e-3)
+	assertFitsTwoLines(t, &o2, 1000)
+}
+
+func TestAdagrad(t *testing.T) {
+	o := NewAdagrad(1e-1)
+	assertFitsLine(t, &o, 300)
+	o2 := NewAdagrad(1e-1)
+	assertFitsTwoLines(t, &o2, 300)
+}
+
+// one optimizer fits a line with each of two modules, which are then
+// evaluated without one
+func TestSharedOptimizer(t *testing.T) {
+	a := NewLinearInit(2, 1, "a", rand.New(rand.NewSource(42)))
+	b := NewLinearInit(2, 1, "b", rand.New(rand.NewSource(7)))
+	optimizer := NewAdam(5e-2)
+	for i := 0; i < 300; i++ {
+		fitLine(&a, &optimizer, 1)
+		fitLine(&b, &optimizer, 1)
+	}
+	for _, m := range []*Module{&a, &b} {
+		p := m.Weights()
+		assert.InDelta(t, -1./3., p[0]/p[2], 5e-2)
+		assert.InDelta(t, -1./2., p[1]/p[2], 5e-2)
+
+		Panic(m.Forward([]float64{3, 0}))
+		assert.InDelta(t, 0, m.Graph.Outputs[0].Val, 5e-2)
+		m.Graph.ZeroGrad()
+		Panic(m.Backprop([]float64{1}, nil))
+		assert.Equal(t, p, m.Weights())
+	}
+	assert.NotEqual(t, a.Weights(), b.Weights())
+	assert.Len(t, optimizer.m, 6)
+}
+
+// zero values step without their constructors, and with zero decay rates and
+// epsilon every adaptive one moves by the learning rate
+func TestZeroOptimizers(t *testing.T) {
+	for _, c := range []struct {
+		o    Optimizer
+		want float64
+	}{
+		{&SGD{}, 1 - 0.2},
+		{&Adam{}, 1 - 0.1},
+		{&AdamW{}, 1 - 0.1},
+		{&RMSProp{}, 1 - 0.1},
+		{&Adagrad{}, 1 - 0.1},
+	} {
+		p := &Node{Label: "p", Val: 1, Grad: 2}
+		c.o.SetLearningRate(0.1)
+		assert.NotPanics(t, func() {
+			c.o.Step([](*Node){p})
+		})
+		assert.InDelta(t, c.want, p.Val, 1e-12)
+	}
+}
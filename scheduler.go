@@ -0,0 +1,191 @@
+package nngo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Scheduler changes the learning rate of an optimizer over the course of
+// training. Step is called once per step or once per epoch, whichever unit
+// the schedule is expressed in, and reports the latest loss, which only
+// ReduceOnPlateau looks at.
+type Scheduler interface {
+	Step(loss float64)
+	// History returns the learning rate set after every step, starting with
+	// the rate the optimizer had when the scheduler was created.
+	History() []float64
+}
+
+type baseScheduler struct {
+	Optimizer Optimizer
+	BaseRate  float64
+	steps     int
+	history   []float64
+}
+
+func newBaseScheduler(optimizer Optimizer) baseScheduler {
+	rate := optimizer.GetLearningRate()
+	return baseScheduler{
+		Optimizer: optimizer,
+		BaseRate:  rate,
+		history:   []float64{rate},
+	}
+}
+
+func (s *baseScheduler) History() []float64 {
+	return s.history
+}
+
+func (s *baseScheduler) set(rate float64) {
+	s.Optimizer.SetLearningRate(rate)
+	Append(&s.history, rate)
+}
+
+// StepDecay multiplies the rate by Gamma every StepSize steps.
+type StepDecay struct {
+	baseScheduler
+	StepSize int
+	Gamma    float64
+}
+
+func NewStepDecay(optimizer Optimizer, stepSize int, gamma float64) (s *StepDecay, err error) {
+	if stepSize <= 0 {
+		err = fmt.Errorf("error step size must be positive, got %d", stepSize)
+		return
+	}
+	s = &StepDecay{
+		baseScheduler: newBaseScheduler(optimizer),
+		StepSize:      stepSize,
+		Gamma:         gamma,
+	}
+	return
+}
+
+func (s *StepDecay) Step(float64) {
+	s.steps++
+	s.set(s.BaseRate * math.Pow(s.Gamma, float64(s.steps/s.StepSize)))
+}
+
+// ExponentialDecay multiplies the rate by Gamma every step.
+type ExponentialDecay struct {
+	baseScheduler
+	Gamma float64
+}
+
+func NewExponentialDecay(optimizer Optimizer, gamma float64) *ExponentialDecay {
+	return &ExponentialDecay{
+		baseScheduler: newBaseScheduler(optimizer),
+		Gamma:         gamma,
+	}
+}
+
+func (s *ExponentialDecay) Step(float64) {
+	s.steps++
+	s.set(s.BaseRate * math.Pow(s.Gamma, float64(s.steps)))
+}
+
+// CosineAnnealing follows half a cosine from the base rate down to MinRate
+// over Period steps, then restarts from the base rate with the period
+// multiplied by PeriodMult.
+type CosineAnnealing struct {
+	baseScheduler
+	Period     int
+	PeriodMult int
+	MinRate    float64
+	sinceStart int
+}
+
+func NewCosineAnnealing(optimizer Optimizer, period, periodMult int, minRate float64) (s *CosineAnnealing, err error) {
+	if period <= 0 {
+		err = fmt.Errorf("error period must be positive, got %d", period)
+		return
+	}
+	s = &CosineAnnealing{
+		baseScheduler: newBaseScheduler(optimizer),
+		Period:        period,
+		PeriodMult:    periodMult,
+		MinRate:       minRate,
+	}
+	return
+}
+
+func (s *CosineAnnealing) Step(float64) {
+	s.steps++
+	s.sinceStart++
+	if s.sinceStart >= s.Period {
+		s.sinceStart = 0
+		s.Period *= Max(1, s.PeriodMult)
+	}
+	progress := float64(s.sinceStart) / float64(s.Period)
+	s.set(s.MinRate + (s.BaseRate-s.MinRate)*(1+math.Cos(math.Pi*progress))/2)
+}
+
+// LinearWarmup raises the rate linearly from zero to the base rate over the
+// first WarmupSteps steps and keeps it there afterwards. It starts the
+// optimizer at the rate of the first step, which follows the base rate in
+// its History.
+type LinearWarmup struct {
+	baseScheduler
+	WarmupSteps int
+}
+
+func NewLinearWarmup(optimizer Optimizer, warmupSteps int) (s *LinearWarmup, err error) {
+	if warmupSteps <= 0 {
+		err = fmt.Errorf("error warmup steps must be positive, got %d", warmupSteps)
+		return
+	}
+	s = &LinearWarmup{
+		baseScheduler: newBaseScheduler(optimizer),
+		WarmupSteps:   warmupSteps,
+	}
+	s.set(s.rate())
+	return
+}
+
+func (s *LinearWarmup) rate() float64 {
+	return s.BaseRate * Min(1, float64(s.steps+1)/float64(s.WarmupSteps))
+}
+
+func (s *LinearWarmup) Step(float64) {
+	s.steps++
+	s.set(s.rate())
+}
+
+// ReduceOnPlateau multiplies the rate by Factor, down to MinRate, once the
+// loss has not improved on its best value by more than a relative Threshold
+// for Patience steps in a row.
+type ReduceOnPlateau struct {
+	baseScheduler
+	Factor    float64
+	Patience  int
+	Threshold float64
+	MinRate   float64
+	best      float64
+	bad       int
+}
+
+func NewReduceOnPlateau(optimizer Optimizer, factor float64, patience int) *ReduceOnPlateau {
+	return &ReduceOnPlateau{
+		baseScheduler: newBaseScheduler(optimizer),
+		Factor:        factor,
+		Patience:      patience,
+		Threshold:     1e-4,
+		best:          math.Inf(1),
+	}
+}
+
+func (s *ReduceOnPlateau) Step(loss float64) {
+	s.steps++
+	if loss < s.best*(1-s.Threshold) {
+		s.best = loss
+		s.bad = 0
+	} else {
+		s.bad++
+	}
+	rate := s.Optimizer.GetLearningRate()
+	if s.bad > s.Patience {
+		rate = math.Max(s.MinRate, rate*s.Factor)
+		s.bad = 0
+	}
+	s.set(rate)
+}
@@ -0,0 +1,101 @@
+package nngo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func stepN(s Scheduler, losses ...float64) {
+	for _, loss := range losses {
+		s.Step(loss)
+	}
+}
+
+func TestStepDecay(t *testing.T) {
+	o := NewOptimizer(1)
+	s, err := NewStepDecay(&o, 2, 0.5)
+	Panic(err)
+	stepN(s, 0, 0, 0, 0, 0)
+	assert.Equal(t, []float64{1, 1, 0.5, 0.5, 0.25, 0.25}, s.History())
+	assert.Equal(t, 0.25, o.LearningRate)
+
+	_, err = NewStepDecay(&o, 0, 0.5)
+	assert.Error(t, err)
+}
+
+func TestExponentialDecay(t *testing.T) {
+	o := NewAdam(1)
+	s := NewExponentialDecay(&o, 0.5)
+	stepN(s, 0, 0, 0)
+	assert.Equal(t, []float64{1, 0.5, 0.25, 0.125}, s.History())
+	assert.Equal(t, 0.125, o.LearningRate)
+}
+
+func TestCosineAnnealing(t *testing.T) {
+	o := NewOptimizer(1)
+	s, err := NewCosineAnnealing(&o, 4, 2, 0)
+	Panic(err)
+	stepN(s, 0, 0, 0, 0, 0, 0, 0, 0)
+	want := []float64{
+		1, 0.5 + 0.5*math.Cos(math.Pi/4), 0.5, 0.5 - 0.5*math.Cos(math.Pi/4),
+		// restart with a period of 8
+		1, 0.5 + 0.5*math.Cos(math.Pi/8), 0.5 + 0.5*math.Cos(math.Pi/4), 0.5 + 0.5*math.Cos(3*math.Pi/8), 0.5,
+	}
+	assert.InDeltaSlice(t, want, s.History(), 1e-12)
+
+	_, err = NewCosineAnnealing(&o, 0, 2, 0)
+	assert.Error(t, err)
+}
+
+func TestLinearWarmup(t *testing.T) {
+	o := NewOptimizer(1)
+	s, err := NewLinearWarmup(&o, 4)
+	Panic(err)
+	assert.Equal(t, 0.25, o.LearningRate)
+	stepN(s, 0, 0, 0, 0)
+	assert.Equal(t, []float64{1, 0.25, 0.5, 0.75, 1, 1}, s.History())
+
+	for _, steps := range []int{0, -1} {
+		_, err = NewLinearWarmup(&o, steps)
+		assert.Error(t, err)
+	}
+}
+
+func TestReduceOnPlateau(t *testing.T) {
+	o := NewOptimizer(1)
+	s := NewReduceOnPlateau(&o, 0.1, 1)
+	s.MinRate = 0.005
+	stepN(s, 5, 4, 4, 4, 3, 3, 3, 3, 3)
+	want := []float64{1, 1, 1, 1, 0.1, 0.1, 0.1, 0.01, 0.01, 0.005}
+	assert.InDeltaSlice(t, want, s.History(), 1e-12)
+}
+
+// warmup then decay per epoch while fitting the line of TestBackProp3
+func TestSchedulerTraining(t *testing.T) {
+	linear := NewLinearInit(2, 1, "l", rand.New(rand.NewSource(42)))
+	optimizer := NewSGD(5e-2, 0.5, false)
+	decay, err := NewStepDecay(&optimizer, 50, 0.5)
+	Panic(err)
+	warmup, err := NewLinearWarmup(&optimizer, 5)
+	Panic(err)
+	var losses []float64
+	for epoch := 0; epoch < 200; epoch++ {
+		loss := fitLine(&linear, &optimizer, 1)[0]
+		Append(&losses, loss)
+		if epoch < 5 {
+			warmup.Step(loss)
+		} else {
+			decay.Step(loss)
+		}
+	}
+	assert.InDeltaSlice(t, []float64{5e-2, 1e-2, 2e-2, 3e-2, 4e-2, 5e-2, 5e-2}, warmup.History(), 1e-12)
+	assert.Len(t, decay.History(), 196)
+	assert.Equal(t, 5e-2/8, optimizer.LearningRate)
+	assert.Less(t, losses[len(losses)-1], 1e-6)
+	p := linear.Weights()
+	assert.InDelta(t, -1./3., p[0]/p[2], 1e-3)
+	assert.InDelta(t, -1./2., p[1]/p[2], 1e-3)
+}
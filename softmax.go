@@ -0,0 +1,26 @@
+package nngo
+
+import "fmt"
+
+// LogSoftMax computes log(softmax(x)) as x - max(x) - log(Σ exp(x - max(x))),
+// which stays finite where the softmax itself underflows to 0.
+func LogSoftMax(n int, label string) Graph {
+	b := NewBuilder(label)
+	inputs := softMaxInputs(b, n, label)
+	m := b.Max(inputs...)
+	shifted := Map(inputs, func(x *Node) *Node {
+		return difference(b, x, m)
+	})
+	lse := b.Log(b.Add(Map(shifted, b.Exp)...))
+	return b.Build(Map(shifted, func(x *Node) *Node {
+		return difference(b, x, lse)
+	})...)
+}
+
+func softMaxInputs(b *Builder, n int, label string) [](*Node) {
+	inputs := make([](*Node), n)
+	for i := range inputs {
+		inputs[i] = b.Input(fmt.Sprintf("%s-input-%d", label, i))
+	}
+	return inputs
+}
@@ -0,0 +1,88 @@
+package nngo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func outputVals(g Graph) []float64 {
+	return Map(g.Outputs, func(n *Node) float64 {
+		return n.Val
+	})
+}
+
+func inputGrads(g Graph) []float64 {
+	return Map(g.Inputs, func(n *Node) float64 {
+		return n.Grad
+	})
+}
+
+// shifting the inputs by 1000 changes nothing, where exp overflows
+func TestSoftMax(t *testing.T) {
+	s := SoftMax(3, "s")
+	assert.NoError(t, s.Validate())
+	total := math.Exp(1) + math.Exp(2) + math.Exp(3)
+	want := []float64{math.Exp(1) / total, math.Exp(2) / total, math.Exp(3) / total}
+	mismatches, err := GradCheck(&s, []float64{1, 2, 3}, []float64{1, -1, 2}, 1e-6, 1e-6)
+	Panic(err)
+	assert.Empty(t, mismatches)
+	wantGrads := inputGrads(s)
+
+	for _, shift := range []float64{0, 1000, -5000} {
+		Panic(s.Forward([]float64{1 + shift, 2 + shift, 3 + shift}))
+		assert.InDeltaSlice(t, want, outputVals(s), 1e-12)
+		s.ZeroGrad()
+		Panic(s.Backprop([]float64{1, -1, 2}))
+		assert.InDeltaSlice(t, wantGrads, inputGrads(s), 1e-12)
+	}
+}
+
+func TestLogSoftMax(t *testing.T) {
+	g := LogSoftMax(3, "l")
+	assert.NoError(t, g.Validate())
+	inputs := []float64{0.5, -1, 2}
+	Panic(g.Forward(inputs))
+	lse := math.Log(math.Exp(0.5) + math.Exp(-1) + math.Exp(2))
+	assert.InDeltaSlice(t, []float64{0.5 - lse, -1 - lse, 2 - lse}, outputVals(g), 1e-12)
+	mismatches, err := GradCheck(&g, inputs, []float64{1, 0, 0}, 1e-6, 1e-6)
+	Panic(err)
+	assert.Empty(t, mismatches)
+
+	// exp(-3000) underflows, its log does not
+	Panic(g.Forward([]float64{0, 3000, 1000}))
+	assert.InDeltaSlice(t, []float64{-3000, 0, -2000}, outputVals(g), 1e-9)
+}
+
+func TestSoftMaxCrossEntropy(t *testing.T) {
+	g := SoftMaxCrossEntropyLoss(3, "ce")
+	assert.NoError(t, g.Validate())
+	inputs := []float64{0.5, -1, 2, 0.2, 0.3, 0.5}
+	Panic(g.Forward(inputs))
+
+	// matches the cross-entropy of the softmax
+	s, err := Compose(SoftMax(3, "s"), CrossEntropyLoss(3, "l"),
+		Wire{From: "s-output-0", To: "l-prediction-0"},
+		Wire{From: "s-output-1", To: "l-prediction-1"},
+		Wire{From: "s-output-2", To: "l-prediction-2"},
+	)
+	Panic(err)
+	Panic(s.Forward(inputs))
+	assert.InDelta(t, s.Outputs[0].Val, g.Outputs[0].Val, 1e-12)
+	s.ZeroGrad()
+	Panic(s.Backprop([]float64{1}))
+	g.ZeroGrad()
+	Panic(g.Backprop([]float64{1}))
+	assert.InDeltaSlice(t, inputGrads(s), inputGrads(g), 1e-12)
+	mismatches, err := GradCheck(&g, inputs, []float64{1}, 1e-6, 1e-6)
+	Panic(err)
+	assert.Empty(t, mismatches)
+
+	// the gradient is p - y even for logits in the thousands
+	Panic(g.Forward([]float64{3000, 1000, 3000 + math.Log(3), 0, 1, 0}))
+	assert.InDelta(t, 2000+math.Log(4), g.Outputs[0].Val, 1e-9)
+	g.ZeroGrad()
+	Panic(g.Backprop([]float64{1}))
+	assert.InDeltaSlice(t, []float64{0.25, -1, 0.75}, inputGrads(g)[:3], 1e-12)
+}
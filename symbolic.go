@@ -0,0 +1,424 @@
+package nngo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Expr is a symbolic expression: a variable named after a graph input, a
+// constant, or an op applied to argument expressions.
+type Expr struct {
+	Op    Op
+	Param float64
+	Args  []*Expr
+	// Var is the label of the input a variable stands for.
+	Var string
+	// Val is the value of a constant.
+	Val float64
+}
+
+func (e *Expr) IsVar() bool {
+	return e.Op == "" && e.Var != ""
+}
+
+func (e *Expr) IsConst() bool {
+	return e.Op == "" && e.Var == ""
+}
+
+// Symbolic holds the expression of every output of a graph and of its
+// partial derivative with respect to every input, as built by
+// Graph.Symbolic.
+type Symbolic struct {
+	Inputs   []string
+	Outputs  []string
+	Exprs    []*Expr
+	Partials [][]*Expr
+}
+
+// Symbolic walks the graph and returns the simplified expression of every
+// output and of each of its partial derivatives, which are read off the
+// GradGraph of g. Nodes that do not depend on the inputs appear as constants
+// with their current values.
+func (g *Graph) Symbolic() (s Symbolic, err error) {
+	d, err := g.GradGraph("symbolic")
+	if err != nil {
+		return
+	}
+	s.Inputs = Map(g.Inputs, func(n *Node) string {
+		return n.Label
+	})
+	s.Outputs = Map(g.Outputs, func(n *Node) string {
+		return n.Label
+	})
+
+	vars := map[*Node]*Expr{}
+	for _, n := range g.Inputs {
+		vars[n] = &Expr{Var: n.Label}
+	}
+	memo := map[*Node]*Expr{}
+	s.Exprs = Map(g.Outputs, func(n *Node) *Expr {
+		return exprOf(n, vars, memo)
+	})
+
+	// the copies of the inputs in d are the same variables, and each output
+	// is differentiated on its own by setting its upstream gradient to 1
+	upstream := d.Inputs[len(g.Inputs):]
+	for i := range g.Outputs {
+		vars := map[*Node]*Expr{}
+		for j, n := range g.Inputs {
+			vars[d.Inputs[j]] = &Expr{Var: n.Label}
+		}
+		for j, u := range upstream {
+			vars[u] = &Expr{}
+			if i == j {
+				vars[u].Val = 1
+			}
+		}
+		memo := map[*Node]*Expr{}
+		Append(&s.Partials, Map(d.Outputs, func(n *Node) *Expr {
+			return exprOf(n, vars, memo)
+		}))
+	}
+	return
+}
+
+// String lists each output as "f = ..." followed by its partial derivatives
+// as "∂f/∂x = ...", one per line.
+func (s Symbolic) String() string {
+	var lines []string
+	for i, out := range s.Outputs {
+		Append(&lines, fmt.Sprintf("%s = %s", out, s.Exprs[i]))
+		for j, inp := range s.Inputs {
+			Append(&lines, fmt.Sprintf("∂%s/∂%s = %s", out, inp, s.Partials[i][j]))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LaTeX lists the same equations as String in LaTeX, one per line.
+func (s Symbolic) LaTeX() string {
+	var lines []string
+	for i, out := range s.Outputs {
+		Append(&lines, fmt.Sprintf("%s = %s", latexVar(out), s.Exprs[i].LaTeX()))
+		for j, inp := range s.Inputs {
+			Append(&lines, fmt.Sprintf("\\frac{\\partial %s}{\\partial %s} = %s", latexVar(out), latexVar(inp), s.Partials[i][j].LaTeX()))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func exprOf(n *Node, vars, memo map[*Node]*Expr) *Expr {
+	if e, ok := vars[n]; ok {
+		return e
+	}
+	if e, ok := memo[n]; ok {
+		return e
+	}
+	var e *Expr
+	switch {
+	case n.Op == "" && len(n.Inputs) == 1:
+		e = exprOf(n.Inputs[0], vars, memo)
+	case n.Op == "":
+		e = &Expr{Val: n.Val}
+	default:
+		args := Map(n.Inputs, func(inp *Node) *Expr {
+			return exprOf(inp, vars, memo)
+		})
+		e = simplify(n.Op, n.Param, args)
+	}
+	memo[n] = e
+	return e
+}
+
+// simplify builds op applied to args, folding constants, dropping zero terms
+// and unit factors and flattening nested sums and products.
+func simplify(op Op, param float64, args []*Expr) *Expr {
+	switch op {
+	case Dot:
+		d := len(args) / 2
+		terms := make([]*Expr, d)
+		for i := range terms {
+			terms[i] = simplify(Multiply, 0, []*Expr{args[i], args[i+d]})
+		}
+		return simplify(Add, 0, terms)
+	case Add:
+		var terms []*Expr
+		constant := 0.
+		for _, a := range flatten(Add, args) {
+			if a.IsConst() {
+				constant += a.Val
+			} else {
+				Append(&terms, a)
+			}
+		}
+		if constant != 0 || len(terms) == 0 {
+			Append(&terms, &Expr{Val: constant})
+		}
+		if len(terms) == 1 {
+			return terms[0]
+		}
+		return &Expr{Op: Add, Args: terms}
+	case Multiply:
+		var factors []*Expr
+		var powers []float64
+		constant := 1.
+		for _, a := range flatten(Multiply, args) {
+			if a.IsConst() {
+				constant *= a.Val
+				continue
+			}
+			// repeated factors are collected into a power
+			base, power := a, 1.
+			if a.Op == Pow && len(a.Args) == 1 {
+				base, power = a.Args[0], a.Param
+			}
+			i := 0
+			for i < len(factors) && !base.Equal(factors[i]) {
+				i++
+			}
+			if i == len(factors) {
+				Append(&factors, base)
+				Append(&powers, 0)
+			}
+			powers[i] += power
+		}
+		collected := factors
+		factors = nil
+		for i, base := range collected {
+			if f := simplify(Pow, powers[i], []*Expr{base}); f.IsConst() {
+				constant *= f.Val
+			} else {
+				Append(&factors, f)
+			}
+		}
+		if constant == 0 {
+			return &Expr{}
+		}
+		if constant != 1 || len(factors) == 0 {
+			factors = append([]*Expr{{Val: constant}}, factors...)
+		}
+		if len(factors) == 1 {
+			return factors[0]
+		}
+		return &Expr{Op: Multiply, Args: factors}
+	case Pow:
+		if len(args) == 1 && param == 1 {
+			return args[0]
+		}
+		if len(args) == 1 && param == 0 {
+			return &Expr{Val: 1}
+		}
+	}
+
+	constant := true
+	for _, a := range args {
+		constant = constant && a.IsConst()
+	}
+	if operator, ok := LookupOperator(op); ok && constant {
+		vals := Map(args, func(a *Expr) float64 {
+			return a.Val
+		})
+		if val, err := operator.Forward(vals, param); err == nil {
+			return &Expr{Val: val}
+		}
+	}
+	return &Expr{Op: op, Param: param, Args: args}
+}
+
+// Equal reports whether e and other are the same expression, written the same
+// way.
+func (e *Expr) Equal(other *Expr) bool {
+	if e.Op != other.Op || e.Param != other.Param || e.Var != other.Var || e.Val != other.Val || len(e.Args) != len(other.Args) {
+		return false
+	}
+	for i := range e.Args {
+		if !e.Args[i].Equal(other.Args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func flatten(op Op, args []*Expr) (flat []*Expr) {
+	for _, a := range args {
+		if a.Op == op {
+			Append(&flat, a.Args...)
+		} else {
+			Append(&flat, a)
+		}
+	}
+	return
+}
+
+// precedence orders how tightly expressions bind when printed.
+func (e *Expr) precedence() int {
+	switch {
+	case e.IsConst() && e.Val < 0:
+		return 1
+	case e.Op == Add:
+		return 1
+	case e.Op == Multiply || e.Op == Reciprocal:
+		return 2
+	case e.Op == Pow:
+		return 3
+	}
+	return 4
+}
+
+// negated returns e without a leading factor of -1 and whether it had one,
+// so sums can print a - b.
+func (e *Expr) negated() (*Expr, bool) {
+	switch {
+	case e.IsConst() && e.Val < 0:
+		return &Expr{Val: -e.Val}, true
+	case e.Op == Multiply && e.Args[0].IsConst() && e.Args[0].Val < 0:
+		return simplify(Multiply, 0, append([]*Expr{{Val: -e.Args[0].Val}}, e.Args[1:]...)), true
+	}
+	return e, false
+}
+
+// opsWithParam are the built-in ops whose Param is printed as a last argument
+// even when it is 0. Other ops print it when it is not.
+var opsWithParam = Set[Op]{Elu: true, LeakyRelu: true, Huber: true, opHeaviside: true, opIsMax: true}
+
+func (e *Expr) String() string {
+	wrap := func(a *Expr, prec int) string {
+		if a.precedence() < prec {
+			return "(" + a.String() + ")"
+		}
+		return a.String()
+	}
+	switch {
+	case e.IsVar():
+		return e.Var
+	case e.IsConst():
+		return formatFloat(e.Val)
+	}
+	switch e.Op {
+	case Add:
+		return joinTerms(e.Args, wrap)
+	case Multiply:
+		if a, ok := e.negated(); ok {
+			return "-" + wrap(a, 2)
+		}
+		return strings.Join(Map(e.Args, func(a *Expr) string {
+			return wrap(a, 3)
+		}), " * ")
+	case Reciprocal:
+		return "1 / " + wrap(e.Args[0], 3)
+	case Pow:
+		if len(e.Args) == 2 {
+			return wrap(e.Args[0], 4) + "^" + wrap(e.Args[1], 4)
+		}
+		return wrap(e.Args[0], 4) + "^" + wrap(&Expr{Val: e.Param}, 4)
+	}
+	args := Map(e.Args, func(a *Expr) string {
+		return a.String()
+	})
+	if opsWithParam[e.Op] || e.Param != 0 {
+		Append(&args, formatFloat(e.Param))
+	}
+	return fmt.Sprintf("%s(%s)", e.Op, strings.Join(args, ", "))
+}
+
+func (e *Expr) LaTeX() string {
+	wrap := func(a *Expr, prec int) string {
+		if a.precedence() < prec {
+			return "\\left(" + a.LaTeX() + "\\right)"
+		}
+		return a.LaTeX()
+	}
+	call := func(name string, args ...*Expr) string {
+		return fmt.Sprintf("%s\\left(%s\\right)", name, strings.Join(Map(args, (*Expr).LaTeX), ", "))
+	}
+	switch {
+	case e.IsVar():
+		return latexVar(e.Var)
+	case e.IsConst():
+		return formatFloat(e.Val)
+	}
+	switch e.Op {
+	case Add:
+		return joinTerms(e.Args, wrap)
+	case Multiply:
+		if a, ok := e.negated(); ok {
+			return "-" + wrap(a, 2)
+		}
+		return strings.Join(Map(e.Args, func(a *Expr) string {
+			return wrap(a, 3)
+		}), " \\cdot ")
+	case Reciprocal:
+		return fmt.Sprintf("\\frac{1}{%s}", e.Args[0].LaTeX())
+	case Pow:
+		exponent := &Expr{Val: e.Param}
+		if len(e.Args) == 2 {
+			exponent = e.Args[1]
+		}
+		return fmt.Sprintf("{%s}^{%s}", wrap(e.Args[0], 4), exponent.LaTeX())
+	case Exp:
+		return fmt.Sprintf("e^{%s}", e.Args[0].LaTeX())
+	case Sqrt:
+		return fmt.Sprintf("\\sqrt{%s}", e.Args[0].LaTeX())
+	case Abs:
+		return fmt.Sprintf("\\left|%s\\right|", e.Args[0].LaTeX())
+	case Log:
+		return call("\\log", e.Args...)
+	case Tanh:
+		return call("\\tanh", e.Args...)
+	case Sigmoid:
+		return call("\\sigma", e.Args...)
+	case opNormalCDF:
+		return call("\\Phi", e.Args...)
+	case Maximum:
+		return call("\\max", e.Args...)
+	case XLogY:
+		return fmt.Sprintf("%s \\cdot %s", wrap(e.Args[0], 3), call("\\log", e.Args[1]))
+	}
+	args := e.Args
+	if opsWithParam[e.Op] || e.Param != 0 {
+		args = append(append([]*Expr{}, args...), &Expr{Val: e.Param})
+	}
+	return call(fmt.Sprintf("\\operatorname{%s}", e.Op), args...)
+}
+
+// joinTerms prints a sum, turning terms with a negative leading constant
+// into subtractions. format prints a term wrapped as needed to bind at least
+// as tightly as prec.
+func joinTerms(terms []*Expr, format func(a *Expr, prec int) string) string {
+	var b strings.Builder
+	for i, t := range terms {
+		a, negative := t.negated()
+		switch {
+		case i == 0 && negative:
+			b.WriteString("-")
+		case negative:
+			b.WriteString(" - ")
+		case i > 0:
+			b.WriteString(" + ")
+		}
+		if negative {
+			b.WriteString(format(a, 2))
+		} else {
+			b.WriteString(format(a, 1))
+		}
+	}
+	return b.String()
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return fmt.Sprint(v)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// latexVar prints single letter labels as they are and longer ones upright.
+func latexVar(label string) string {
+	if len([]rune(label)) == 1 {
+		return label
+	}
+	r := strings.NewReplacer("-", "{-}", "_", "\\_", "%", "\\%", "#", "\\#", "&", "\\&", "$", "\\$")
+	return fmt.Sprintf("\\mathrm{%s}", r.Replace(label))
+}
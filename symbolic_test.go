@@ -0,0 +1,90 @@
+package nngo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// f(x,y,z) = (x+y)*z as in TestBackProp1
+func TestSymbolic1(t *testing.T) {
+	var a, b Node
+	x := InputSymbol("x", [](*Node){&a})
+	y := InputSymbol("y", [](*Node){&a})
+	z := InputSymbol("z", [](*Node){&b})
+	f := OutputSymbol("f", &b)
+	a = AddNode("a", [](*Node){&b}, [](*Node){&x, &y})
+	b = MultiplyNode("b", [](*Node){&f}, [](*Node){&a, &z})
+	graph := NewGraph([](*Node){&z, &x, &y}, [](*Node){&f}, [](*Node){&a, &b})
+
+	s, err := graph.Symbolic()
+	Panic(err)
+	assert.Equal(t, "(x + y) * z", s.Exprs[0].String())
+	assert.Equal(t, "z", s.Partials[0][1].String())
+	assert.Equal(t, "f = (x + y) * z\n∂f/∂z = x + y\n∂f/∂x = z\n∂f/∂y = z", s.String())
+	assert.Equal(t, `f = \left(x + y\right) \cdot z
+\frac{\partial f}{\partial z} = x + y
+\frac{\partial f}{\partial x} = z
+\frac{\partial f}{\partial y} = z`, s.LaTeX())
+}
+
+// constants are folded, zero terms and unit factors dropped, and negative
+// terms printed as subtractions
+func TestSymbolic2(t *testing.T) {
+	b := NewBuilder("g")
+	x, y := b.Input("x"), b.Input("y")
+	g := b.Build(
+		b.Add(b.Mul(b.Const(2), b.Const(3), x), b.Mul(b.Const(0), y), b.Const(1)),
+		difference(b, b.Exp(b.Mul(x, y)), b.Pow(y, 2)),
+		b.Reciprocal(b.Sqrt(x)),
+	)
+	s, err := g.Symbolic()
+	Panic(err)
+	assert.Equal(t, []string{
+		"g-output-0 = 6 * x + 1",
+		"∂g-output-0/∂x = 6",
+		"∂g-output-0/∂y = 0",
+		"g-output-1 = exp(x * y) - y^2",
+		"∂g-output-1/∂x = exp(x * y) * y",
+		"∂g-output-1/∂y = -2 * y + exp(x * y) * x",
+		"g-output-2 = 1 / sqrt(x)",
+		"∂g-output-2/∂x = -0.5 * (1 / sqrt(x))^3",
+		"∂g-output-2/∂y = 0",
+	}, splitLines(s.String()))
+	assert.Equal(t, `\mathrm{g{-}output{-}1} = e^{x \cdot y} - {y}^{2}`, splitLines(s.LaTeX())[3])
+}
+
+// every node of a layer and activation, including the unit node as a
+// constant
+func TestSymbolic3(t *testing.T) {
+	b := NewBuilder("h")
+	x := b.Input("x")
+	g := b.Build(b.LeakyRelu(b.Dot([](*Node){x, b.Const(1)}, [](*Node){b.Const(3), b.Const(-2)}), 0.1))
+	s, err := g.Symbolic()
+	Panic(err)
+	assert.Equal(t, "leaky-relu(3 * x - 2, 0.1)", s.Exprs[0].String())
+	assert.Equal(t, "3 * heaviside(3 * x - 2, 0.1)", s.Partials[0][0].String())
+	assert.Equal(t, `\operatorname{leaky-relu}\left(3 \cdot x - 2, 0.1\right)`, s.Exprs[0].LaTeX())
+
+	linear := NewLinearInit(1, 1, "l", rand.New(rand.NewSource(42)))
+	s, err = linear.Graph.Symbolic()
+	Panic(err)
+	assert.Equal(t, "l-input-0 * l-weight-0 + l-bias-0", s.Exprs[0].String())
+
+	x2 := &Expr{Op: Pow, Param: 2, Args: []*Expr{{Var: "x"}}}
+	e := simplify(Multiply, 0, []*Expr{{Val: 3}, x2, {Op: Pow, Param: -2, Args: []*Expr{{Var: "x"}}}})
+	assert.Equal(t, "3", e.String())
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	start := 0
+	for i := range s {
+		if s[i] == '\n' {
+			Append(&lines, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(lines, s[start:])
+}
@@ -0,0 +1,179 @@
+package nngo
+
+import "fmt"
+
+// Tape is a Graph lowered into a flat list of instructions over integer
+// slots, so Forward and Backward run as plain loops without chasing node
+// pointers, looking up operators or allocating.
+type Tape struct {
+	vals         []float64
+	grads        []float64
+	instructions []instruction
+	args         []int
+	inputs       []int
+	outputs      []int
+	slots        map[*Node]int
+	labels       []string
+	scratch      []float64
+	partials     []float64
+}
+
+// instruction computes vals[out] from the values of the slots in
+// args[start:end]. A nil op copies its single argument, which is how symbols
+// are lowered.
+type instruction struct {
+	op         Operator
+	param      float64
+	start, end int
+	out        int
+}
+
+// Compile lowers the graph into a Tape. Nodes that are not computed from the
+// graph inputs, like the unit node of NewLinear, keep the value they have at
+// compile time.
+func (g *Graph) Compile() (t *Tape, err error) {
+	t = &Tape{slots: map[*Node]int{}}
+	slot := func(n *Node) int {
+		i, ok := t.slots[n]
+		if !ok {
+			i = len(t.vals)
+			t.slots[n] = i
+			Append(&t.vals, n.Val)
+			Append(&t.labels, n.Label)
+		}
+		return i
+	}
+
+	t.inputs = Map(g.Inputs, slot)
+	isInput := Set[*Node]{}
+	for _, n := range g.Inputs {
+		isInput[n] = true
+	}
+
+	maxArity := 1
+	for _, n := range g.Schedule() {
+		if isInput[n] || len(n.Inputs) == 0 {
+			continue
+		}
+		err = checkArity(n)
+		if err != nil {
+			return
+		}
+		ins := instruction{param: n.Param}
+		if n.Op != "" {
+			ins.op, _ = LookupOperator(n.Op)
+		}
+		ins.start = len(t.args)
+		Append(&t.args, Map(n.Inputs, slot)...)
+		ins.end = len(t.args)
+		ins.out = slot(n)
+		maxArity = Max(maxArity, len(n.Inputs))
+		Append(&t.instructions, ins)
+	}
+	t.outputs = Map(g.Outputs, slot)
+
+	t.grads = make([]float64, len(t.vals))
+	t.scratch = make([]float64, maxArity)
+	t.partials = make([]float64, maxArity)
+	return
+}
+
+func (t *Tape) Forward(inputValues []float64) (err error) {
+	if len(inputValues) != len(t.inputs) {
+		err = fmt.Errorf("error list of values must match list of inputs")
+		return
+	}
+	for i, slot := range t.inputs {
+		t.vals[slot] = inputValues[i]
+	}
+	for i := range t.instructions {
+		ins := &t.instructions[i]
+		args := t.args[ins.start:ins.end]
+		if ins.op == nil {
+			t.vals[ins.out] = t.vals[args[0]]
+			continue
+		}
+		x := t.gather(args)
+		t.vals[ins.out], err = ins.op.Forward(x, ins.param)
+		if err != nil {
+			err = fmt.Errorf("%w at node %s", err, t.labels[ins.out])
+			return
+		}
+	}
+	return
+}
+
+// Backward computes the gradients of every slot from upstreamGrads, the
+// gradients of the outputs. Unlike Graph.Backprop it starts from zero on every
+// call, so there is no need for ZeroGrad.
+func (t *Tape) Backward(upstreamGrads []float64) (err error) {
+	if len(upstreamGrads) != len(t.outputs) {
+		err = fmt.Errorf("error list of upstream gradients must match list of outputs")
+		return
+	}
+	for i := range t.grads {
+		t.grads[i] = 0
+	}
+	for i, slot := range t.outputs {
+		t.grads[slot] = upstreamGrads[i]
+	}
+	for i := len(t.instructions) - 1; i >= 0; i-- {
+		ins := &t.instructions[i]
+		args := t.args[ins.start:ins.end]
+		grad := t.grads[ins.out]
+		if ins.op == nil {
+			t.grads[args[0]] += grad
+			continue
+		}
+		x := t.gather(args)
+		partials := t.partials[:len(args)]
+		ins.op.Backward(x, t.vals[ins.out], ins.param, partials)
+		for j, arg := range args {
+			t.grads[arg] += grad * partials[j]
+		}
+	}
+	return
+}
+
+func (t *Tape) gather(args []int) []float64 {
+	x := t.scratch[:len(args)]
+	for j, arg := range args {
+		x[j] = t.vals[arg]
+	}
+	return x
+}
+
+func (t *Tape) Output(i int) float64 {
+	return t.vals[t.outputs[i]]
+}
+
+func (t *Tape) InputGrad(i int) float64 {
+	return t.grads[t.inputs[i]]
+}
+
+// Value returns the value of a node of the compiled graph.
+func (t *Tape) Value(n *Node) (val float64, ok bool) {
+	i, ok := t.slots[n]
+	if ok {
+		val = t.vals[i]
+	}
+	return
+}
+
+// Gradient returns the gradient of a node of the compiled graph.
+func (t *Tape) Gradient(n *Node) (grad float64, ok bool) {
+	i, ok := t.slots[n]
+	if ok {
+		grad = t.grads[i]
+	}
+	return
+}
+
+// WriteBack copies the values and gradients held by the tape into the nodes
+// of the compiled graph.
+func (t *Tape) WriteBack() {
+	for n, i := range t.slots {
+		n.Val = t.vals[i]
+		n.Grad = t.grads[i]
+	}
+}
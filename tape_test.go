@@ -0,0 +1,113 @@
+package nngo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertTapeMatchesGraph(t *testing.T, g Graph, inputs, upstream []float64) {
+	tape, err := g.Compile()
+	Panic(err)
+
+	Panic(g.Forward(inputs))
+	g.ZeroGrad()
+	Panic(g.Backprop(upstream))
+
+	Panic(tape.Forward(inputs))
+	Panic(tape.Backward(upstream))
+
+	for i, out := range g.Outputs {
+		assert.InDelta(t, out.Val, tape.Output(i), 1e-12, out.Label)
+	}
+	for i, inp := range g.Inputs {
+		assert.InDelta(t, inp.Grad, tape.InputGrad(i), 1e-12, inp.Label)
+	}
+	for _, n := range g.Intermediates {
+		grad, ok := tape.Gradient(n)
+		assert.True(t, ok, n.Label)
+		assert.InDelta(t, n.Grad, grad, 1e-12, n.Label)
+	}
+}
+
+func TestTape1(t *testing.T) {
+	linear := NewLinearInit(3, 2, "l", rand.New(rand.NewSource(42)))
+	assertTapeMatchesGraph(t, linear.Graph, []float64{4, -6, 7, -1, 5, 2, 1, 3, 0.5, -2, 1}, []float64{1, -2})
+
+	s := Merge([]Graph{SoftMax(3, "s1"), SoftMax(3, "s2")})
+	assertTapeMatchesGraph(t, s, []float64{1, 2, 3}, []float64{1, 1.5, 2})
+
+	b := NewBuilder("f")
+	x, y := b.Input("x"), b.Input("y")
+	g := b.Build(b.Mul(x, x, y), b.Log(b.Add(b.Sigmoid(x), b.Const(1))))
+	assertTapeMatchesGraph(t, g, []float64{0, 2}, []float64{1, 3})
+}
+
+func TestTape2(t *testing.T) {
+	b := NewBuilder("f")
+	x := b.Input("x")
+	g := b.Build(b.Sqrt(x))
+	tape, err := g.Compile()
+	Panic(err)
+
+	assert.ErrorIs(t, tape.Forward([]float64{-1}), ErrDomain)
+	assert.Error(t, tape.Forward([]float64{1, 2}))
+	assert.Error(t, tape.Backward([]float64{}))
+
+	Panic(tape.Forward([]float64{4}))
+	Panic(tape.Backward([]float64{1}))
+	tape.WriteBack()
+	assert.Equal(t, 2.0, g.Outputs[0].Val)
+	assert.Equal(t, 0.25, x.Grad)
+}
+
+func benchmarkInputs(n int) []float64 {
+	r := rand.New(rand.NewSource(42))
+	vals := make([]float64, n)
+	for i := range vals {
+		vals[i] = RandomFloat64(r, -1, 1)
+	}
+	return vals
+}
+
+func benchmarkGraph(b *testing.B, g Graph) {
+	inputs := benchmarkInputs(len(g.Inputs))
+	upstream := benchmarkInputs(len(g.Outputs))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Panic(g.Forward(inputs))
+		g.ZeroGrad()
+		Panic(g.Backprop(upstream))
+	}
+}
+
+func benchmarkTape(b *testing.B, g Graph) {
+	tape, err := g.Compile()
+	Panic(err)
+	inputs := benchmarkInputs(len(g.Inputs))
+	upstream := benchmarkInputs(len(g.Outputs))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Panic(tape.Forward(inputs))
+		Panic(tape.Backward(upstream))
+	}
+}
+
+func BenchmarkGraphLinear(b *testing.B) {
+	benchmarkGraph(b, NewLinearInit(64, 32, "l", rand.New(rand.NewSource(42))).Graph)
+}
+
+func BenchmarkTapeLinear(b *testing.B) {
+	benchmarkTape(b, NewLinearInit(64, 32, "l", rand.New(rand.NewSource(42))).Graph)
+}
+
+func BenchmarkGraphSoftMax(b *testing.B) {
+	benchmarkGraph(b, SoftMax(64, "s"))
+}
+
+func BenchmarkTapeSoftMax(b *testing.B) {
+	benchmarkTape(b, SoftMax(64, "s"))
+}
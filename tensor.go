@@ -0,0 +1,377 @@
+package nngo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Tensor is an n-dimensional array of float64 stored in Data. Element idx is
+// found at Offset + sum(idx[i] * Strides[i]), so reshapes of contiguous data,
+// transposes, slices and broadcasts are views sharing Data.
+type Tensor struct {
+	Shape   []int
+	Strides []int
+	Data    []float64
+	Offset  int
+}
+
+func NewTensor(data []float64, shape ...int) (t *Tensor, err error) {
+	if len(data) != Product(shape) {
+		err = fmt.Errorf("error %d values do not fill shape %v", len(data), shape)
+		return
+	}
+	t = &Tensor{
+		Shape:   append([]int{}, shape...),
+		Strides: contiguousStrides(shape),
+		Data:    data,
+	}
+	return
+}
+
+func Zeros(shape ...int) *Tensor {
+	t, err := NewTensor(make([]float64, Product(shape)), shape...)
+	Panic(err)
+	return t
+}
+
+func Full(val float64, shape ...int) *Tensor {
+	t := Zeros(shape...)
+	for i := range t.Data {
+		t.Data[i] = val
+	}
+	return t
+}
+
+func contiguousStrides(shape []int) []int {
+	strides := make([]int, len(shape))
+	stride := 1
+	for i := len(shape) - 1; i >= 0; i-- {
+		strides[i] = stride
+		stride *= shape[i]
+	}
+	return strides
+}
+
+func (t *Tensor) Dims() int {
+	return len(t.Shape)
+}
+
+func (t *Tensor) Size() int {
+	return Product(t.Shape)
+}
+
+// offset returns the position of the element at idx in Data. It panics if
+// idx does not have one index per dimension, each within its dimension,
+// rather than reading another element through the strides.
+func (t *Tensor) offset(idx []int) int {
+	if len(idx) != t.Dims() {
+		Panic(fmt.Errorf("error index %v does not match shape %v", idx, t.Shape))
+	}
+	offset := t.Offset
+	for i := range idx {
+		if idx[i] < 0 || idx[i] >= t.Shape[i] {
+			Panic(fmt.Errorf("error index %v out of range for shape %v", idx, t.Shape))
+		}
+		offset += idx[i] * t.Strides[i]
+	}
+	return offset
+}
+
+func (t *Tensor) At(idx ...int) float64 {
+	return t.Data[t.offset(idx)]
+}
+
+func (t *Tensor) Set(val float64, idx ...int) {
+	t.Data[t.offset(idx)] = val
+}
+
+func (t *Tensor) IsContiguous() bool {
+	if t.Offset != 0 || len(t.Data) != t.Size() {
+		return false
+	}
+	strides := contiguousStrides(t.Shape)
+	for i := range strides {
+		if t.Shape[i] > 1 && strides[i] != t.Strides[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Values returns the elements in row-major order.
+func (t *Tensor) Values() []float64 {
+	vals := make([]float64, 0, t.Size())
+	forEachIndex(t.Shape, func(idx []int) {
+		Append(&vals, t.At(idx...))
+	})
+	return vals
+}
+
+// Contiguous returns t itself if its data is laid out in row-major order and
+// a row-major copy otherwise.
+func (t *Tensor) Contiguous() *Tensor {
+	if t.IsContiguous() {
+		return t
+	}
+	c, err := NewTensor(t.Values(), t.Shape...)
+	Panic(err)
+	return c
+}
+
+// Reshape returns a tensor with the same elements in row-major order and the
+// given shape. One dimension may be -1, in which case it is inferred.
+func (t *Tensor) Reshape(shape ...int) (r *Tensor, err error) {
+	shape = append([]int{}, shape...)
+	inferred := -1
+	known := 1
+	for i, d := range shape {
+		switch {
+		case d == -1 && inferred == -1:
+			inferred = i
+		case d < 0:
+			err = fmt.Errorf("error invalid shape %v", shape)
+			return
+		default:
+			known *= d
+		}
+	}
+	if inferred != -1 {
+		if known == 0 {
+			err = fmt.Errorf("error cannot infer a dimension of %v with another of size 0", shape)
+			return
+		}
+		shape[inferred] = t.Size() / known
+	}
+	if Product(shape) != t.Size() {
+		err = fmt.Errorf("error cannot reshape %v into %v", t.Shape, shape)
+		return
+	}
+	c := t.Contiguous()
+	r = &Tensor{Shape: shape, Strides: contiguousStrides(shape), Data: c.Data}
+	return
+}
+
+// Transpose permutes the dimensions of t, so dimension i of the result is
+// dimension axes[i] of t. Without axes the dimensions are reversed.
+func (t *Tensor) Transpose(axes ...int) (r *Tensor, err error) {
+	if len(axes) == 0 {
+		axes = make([]int, t.Dims())
+		for i := range axes {
+			axes[i] = t.Dims() - 1 - i
+		}
+	}
+	if len(axes) != t.Dims() {
+		err = fmt.Errorf("error axes %v do not match shape %v", axes, t.Shape)
+		return
+	}
+	r = &Tensor{
+		Shape:   make([]int, len(axes)),
+		Strides: make([]int, len(axes)),
+		Data:    t.Data,
+		Offset:  t.Offset,
+	}
+	seen := Set[int]{}
+	for i, axis := range axes {
+		if axis < 0 || axis >= t.Dims() || seen[axis] {
+			err = fmt.Errorf("error axes %v are not a permutation of %d dimensions", axes, t.Dims())
+			return
+		}
+		seen[axis] = true
+		r.Shape[i] = t.Shape[axis]
+		r.Strides[i] = t.Strides[axis]
+	}
+	return
+}
+
+// Slice returns the view of t restricted to start <= index < end along axis.
+func (t *Tensor) Slice(axis, start, end int) (r *Tensor, err error) {
+	if axis < 0 || axis >= t.Dims() {
+		err = fmt.Errorf("error axis %d out of range for shape %v", axis, t.Shape)
+		return
+	}
+	if start < 0 || end > t.Shape[axis] || start > end {
+		err = fmt.Errorf("error slice [%d:%d] out of range for dimension of size %d", start, end, t.Shape[axis])
+		return
+	}
+	r = &Tensor{
+		Shape:   append([]int{}, t.Shape...),
+		Strides: append([]int{}, t.Strides...),
+		Data:    t.Data,
+		Offset:  t.Offset + start*t.Strides[axis],
+	}
+	r.Shape[axis] = end - start
+	return
+}
+
+// BroadcastShapes returns the shape that tensors of the given shapes broadcast
+// to, following NumPy: shapes are aligned on their last dimension and each
+// dimension must either match or be 1.
+func BroadcastShapes(shapes ...[]int) (shape []int, err error) {
+	dims := 0
+	for _, s := range shapes {
+		dims = Max(dims, len(s))
+	}
+	shape = make([]int, dims)
+	for i := range shape {
+		shape[i] = 1
+	}
+	for _, s := range shapes {
+		for i := range s {
+			d, j := s[len(s)-1-i], dims-1-i
+			switch {
+			case d == shape[j] || d == 1:
+			case shape[j] == 1:
+				shape[j] = d
+			default:
+				err = fmt.Errorf("error shapes %v cannot be broadcast together", shapes)
+				return
+			}
+		}
+	}
+	return
+}
+
+// BroadcastTo returns a view of t with the given shape, where dimensions t
+// does not have or has with size 1 are repeated through a zero stride.
+func (t *Tensor) BroadcastTo(shape ...int) (r *Tensor, err error) {
+	if len(shape) < t.Dims() {
+		err = fmt.Errorf("error cannot broadcast %v to %v", t.Shape, shape)
+		return
+	}
+	r = &Tensor{
+		Shape:   append([]int{}, shape...),
+		Strides: make([]int, len(shape)),
+		Data:    t.Data,
+		Offset:  t.Offset,
+	}
+	lead := len(shape) - t.Dims()
+	for i := range t.Shape {
+		switch t.Shape[i] {
+		case shape[lead+i]:
+			r.Strides[lead+i] = t.Strides[i]
+		case 1:
+		default:
+			err = fmt.Errorf("error cannot broadcast %v to %v", t.Shape, shape)
+			return
+		}
+	}
+	return
+}
+
+// Elementwise applies a scalar operator to the broadcast inputs, element by
+// element.
+func Elementwise(op Operator, param float64, inputs ...*Tensor) (out *Tensor, err error) {
+	err = op.CheckArity(len(inputs))
+	if err != nil {
+		return
+	}
+	views, shape, err := broadcastAll(inputs)
+	if err != nil {
+		return
+	}
+	out = Zeros(shape...)
+	x := make([]float64, len(inputs))
+	i := 0
+	forEachIndex(shape, func(idx []int) {
+		if err != nil {
+			return
+		}
+		for j, v := range views {
+			x[j] = v.At(idx...)
+		}
+		out.Data[i], err = op.Forward(x, param)
+		i++
+	})
+	return
+}
+
+func broadcastAll(inputs []*Tensor) (views []*Tensor, shape []int, err error) {
+	shape, err = BroadcastShapes(Map(inputs, func(t *Tensor) []int {
+		return t.Shape
+	})...)
+	if err != nil {
+		return
+	}
+	views = make([]*Tensor, len(inputs))
+	for i, inp := range inputs {
+		views[i], err = inp.BroadcastTo(shape...)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Sum adds up the elements along axis, removing that dimension.
+func (t *Tensor) Sum(axis int) (r *Tensor, err error) {
+	return t.reduce(axis, 0, func(acc, x float64) float64 {
+		return acc + x
+	})
+}
+
+// Mean averages the elements along axis, removing that dimension.
+func (t *Tensor) Mean(axis int) (r *Tensor, err error) {
+	r, err = t.Sum(axis)
+	if err != nil {
+		return
+	}
+	for i := range r.Data {
+		r.Data[i] /= float64(t.Shape[axis])
+	}
+	return
+}
+
+// Max takes the largest element along axis, removing that dimension.
+func (t *Tensor) Max(axis int) (r *Tensor, err error) {
+	if axis >= 0 && axis < t.Dims() && t.Shape[axis] == 0 {
+		err = fmt.Errorf("error max over an empty dimension")
+		return
+	}
+	return t.reduce(axis, math.Inf(-1), math.Max)
+}
+
+func (t *Tensor) reduce(axis int, init float64, f func(acc, x float64) float64) (r *Tensor, err error) {
+	if axis < 0 || axis >= t.Dims() {
+		err = fmt.Errorf("error axis %d out of range for shape %v", axis, t.Shape)
+		return
+	}
+	shape := append(append([]int{}, t.Shape[:axis]...), t.Shape[axis+1:]...)
+	r = Full(init, shape...)
+	i := 0
+	forEachIndex(shape, func(idx []int) {
+		full := append(append(append([]int{}, idx[:axis]...), 0), idx[axis:]...)
+		acc := init
+		for k := 0; k < t.Shape[axis]; k++ {
+			full[axis] = k
+			acc = f(acc, t.At(full...))
+		}
+		r.Data[i] = acc
+		i++
+	})
+	return
+}
+
+// forEachIndex calls f with every index of shape in row-major order. The
+// slice passed to f is reused between calls.
+func forEachIndex(shape []int, f func(idx []int)) {
+	for _, d := range shape {
+		if d == 0 {
+			return
+		}
+	}
+	idx := make([]int, len(shape))
+	for {
+		f(idx)
+		i := len(shape) - 1
+		for ; i >= 0; i-- {
+			idx[i]++
+			if idx[i] < shape[i] {
+				break
+			}
+			idx[i] = 0
+		}
+		if i < 0 {
+			return
+		}
+	}
+}
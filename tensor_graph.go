@@ -0,0 +1,370 @@
+package nngo
+
+import "fmt"
+
+const (
+	Reshape    Op = "reshape"
+	Transpose  Op = "transpose"
+	Slice      Op = "slice"
+	ReduceSum  Op = "sum"
+	ReduceMean Op = "mean"
+)
+
+// TensorNode is the tensor valued counterpart of Node. Elementwise nodes carry
+// the Op of a registered scalar Operator and broadcast their inputs, the
+// others reshape, transpose, slice or reduce a single input.
+type TensorNode struct {
+	Label   string
+	Op      Op
+	Inputs  [](*TensorNode)
+	Outputs [](*TensorNode)
+	Val     *Tensor
+	Grad    *Tensor
+	Param   float64
+
+	kernel tensorKernel
+}
+
+// tensorKernel computes a node value from its input values and, given the
+// gradient of the node, the gradient of each input.
+type tensorKernel struct {
+	forward  func(inputs []*Tensor) (*Tensor, error)
+	backward func(inputs []*Tensor, out, grad *Tensor) ([]*Tensor, error)
+}
+
+func (n *TensorNode) inputVals() []*Tensor {
+	return Map(n.Inputs, func(n *TensorNode) *Tensor {
+		return n.Val
+	})
+}
+
+func (n *TensorNode) ComputeVal() (err error) {
+	if n.kernel.forward == nil {
+		return
+	}
+	n.Val, err = n.kernel.forward(n.inputVals())
+	if err != nil {
+		err = fmt.Errorf("%w at node %s", err, n.Label)
+	}
+	return
+}
+
+func (n *TensorNode) ComputeGrad() (err error) {
+	if n.kernel.backward == nil || n.Grad == nil {
+		return
+	}
+	grads, err := n.kernel.backward(n.inputVals(), n.Val, n.Grad)
+	if err != nil {
+		err = fmt.Errorf("%w at node %s", err, n.Label)
+		return
+	}
+	for i, inp := range n.Inputs {
+		inp.accumulateGrad(grads[i])
+	}
+	return
+}
+
+func (n *TensorNode) accumulateGrad(grad *Tensor) {
+	if n.Grad == nil {
+		n.Grad = Zeros(n.Val.Shape...)
+	}
+	i := 0
+	forEachIndex(grad.Shape, func(idx []int) {
+		n.Grad.Data[i] += grad.At(idx...)
+		i++
+	})
+}
+
+func newTensorNode(label string, op Op, kernel tensorKernel, inputs ...*TensorNode) *TensorNode {
+	node := &TensorNode{
+		Label:  label,
+		Op:     op,
+		Inputs: inputs,
+		kernel: kernel,
+	}
+	for _, inp := range inputs {
+		if !contains(inp.Outputs, node) {
+			Append(&inp.Outputs, node)
+		}
+	}
+	return node
+}
+
+// TensorInput is a graph input whose value must have the given shape.
+func TensorInput(label string, shape ...int) *TensorNode {
+	return &TensorNode{Label: label, Val: Zeros(shape...)}
+}
+
+// TensorElementwise applies the registered scalar operator op to the
+// broadcast inputs. Gradients of broadcast inputs are summed over the
+// dimensions they were repeated along.
+func TensorElementwise(label string, op Op, param float64, inputs ...*TensorNode) *TensorNode {
+	var node *TensorNode
+	node = newTensorNode(label, op, tensorKernel{
+		forward: func(inputs []*Tensor) (*Tensor, error) {
+			operator, ok := LookupOperator(op)
+			if !ok {
+				return nil, fmt.Errorf("error unknown op %q", op)
+			}
+			return Elementwise(operator, node.Param, inputs...)
+		},
+		backward: func(inputs []*Tensor, out, grad *Tensor) (grads []*Tensor, err error) {
+			operator, _ := LookupOperator(op)
+			views, shape, err := broadcastAll(inputs)
+			if err != nil {
+				return
+			}
+			// a broadcast view of a gradient accumulates every repetition
+			// of an element into the one it was repeated from
+			grads = make([]*Tensor, len(inputs))
+			gradViews := make([]*Tensor, len(inputs))
+			for j, inp := range inputs {
+				grads[j] = Zeros(inp.Shape...)
+				gradViews[j], err = grads[j].BroadcastTo(shape...)
+				if err != nil {
+					return
+				}
+			}
+			x := make([]float64, len(inputs))
+			partials := make([]float64, len(inputs))
+			forEachIndex(shape, func(idx []int) {
+				for j, v := range views {
+					x[j] = v.At(idx...)
+				}
+				operator.Backward(x, out.At(idx...), node.Param, partials)
+				g := grad.At(idx...)
+				for j, v := range gradViews {
+					v.Data[v.offset(idx)] += g * partials[j]
+				}
+			})
+			return
+		},
+	}, inputs...)
+	node.Param = param
+	return node
+}
+
+func TensorReshape(label string, input *TensorNode, shape ...int) *TensorNode {
+	return newTensorNode(label, Reshape, tensorKernel{
+		forward: func(inputs []*Tensor) (*Tensor, error) {
+			return inputs[0].Reshape(shape...)
+		},
+		backward: func(inputs []*Tensor, _, grad *Tensor) (grads []*Tensor, err error) {
+			g, err := grad.Reshape(inputs[0].Shape...)
+			grads = []*Tensor{g}
+			return
+		},
+	}, input)
+}
+
+// TensorTranspose permutes the dimensions of input, see Tensor.Transpose.
+func TensorTranspose(label string, input *TensorNode, axes ...int) *TensorNode {
+	return newTensorNode(label, Transpose, tensorKernel{
+		forward: func(inputs []*Tensor) (*Tensor, error) {
+			return inputs[0].Transpose(axes...)
+		},
+		backward: func(inputs []*Tensor, _, grad *Tensor) (grads []*Tensor, err error) {
+			inverse := make([]int, inputs[0].Dims())
+			for i := range inverse {
+				inverse[i] = inputs[0].Dims() - 1 - i
+			}
+			if len(axes) > 0 {
+				for i, axis := range axes {
+					inverse[axis] = i
+				}
+			}
+			g, err := grad.Transpose(inverse...)
+			grads = []*Tensor{g}
+			return
+		},
+	}, input)
+}
+
+// TensorSlice keeps the indices start <= i < end of input along axis.
+func TensorSlice(label string, input *TensorNode, axis, start, end int) *TensorNode {
+	return newTensorNode(label, Slice, tensorKernel{
+		forward: func(inputs []*Tensor) (*Tensor, error) {
+			return inputs[0].Slice(axis, start, end)
+		},
+		backward: func(inputs []*Tensor, _, grad *Tensor) (grads []*Tensor, err error) {
+			g := Zeros(inputs[0].Shape...)
+			view, err := g.Slice(axis, start, end)
+			if err != nil {
+				return
+			}
+			forEachIndex(grad.Shape, func(idx []int) {
+				view.Data[view.offset(idx)] = grad.At(idx...)
+			})
+			grads = []*Tensor{g}
+			return
+		},
+	}, input)
+}
+
+// TensorSum adds up input along axis, removing that dimension.
+func TensorSum(label string, input *TensorNode, axis int) *TensorNode {
+	return newTensorNode(label, ReduceSum, tensorKernel{
+		forward: func(inputs []*Tensor) (*Tensor, error) {
+			return inputs[0].Sum(axis)
+		},
+		backward: func(inputs []*Tensor, _, grad *Tensor) ([]*Tensor, error) {
+			return spreadGrad(inputs[0], grad, axis, 1)
+		},
+	}, input)
+}
+
+// TensorMean averages input along axis, removing that dimension.
+func TensorMean(label string, input *TensorNode, axis int) *TensorNode {
+	return newTensorNode(label, ReduceMean, tensorKernel{
+		forward: func(inputs []*Tensor) (*Tensor, error) {
+			return inputs[0].Mean(axis)
+		},
+		backward: func(inputs []*Tensor, _, grad *Tensor) ([]*Tensor, error) {
+			return spreadGrad(inputs[0], grad, axis, 1/float64(inputs[0].Shape[axis]))
+		},
+	}, input)
+}
+
+// spreadGrad hands the gradient of a reduction over axis back to every
+// element that was reduced, scaled by scale.
+func spreadGrad(input, grad *Tensor, axis int, scale float64) (grads []*Tensor, err error) {
+	shape := append(append(append([]int{}, grad.Shape[:axis]...), 1), grad.Shape[axis:]...)
+	kept, err := grad.Reshape(shape...)
+	if err != nil {
+		return
+	}
+	spread, err := kept.BroadcastTo(input.Shape...)
+	if err != nil {
+		return
+	}
+	g, err := NewTensor(spread.Values(), input.Shape...)
+	if err != nil {
+		return
+	}
+	for i := range g.Data {
+		g.Data[i] *= scale
+	}
+	grads = []*Tensor{g}
+	return
+}
+
+type TensorGraph struct {
+	Inputs        [](*TensorNode)
+	Outputs       [](*TensorNode)
+	Intermediates [](*TensorNode)
+
+	forwardSchedule [](*TensorNode)
+	reverseSchedule [](*TensorNode)
+}
+
+// NewTensorGraph collects every node computed from inputs as an intermediate.
+func NewTensorGraph(inputs, outputs [](*TensorNode)) TensorGraph {
+	g := TensorGraph{Inputs: inputs, Outputs: outputs}
+	isInput := Set[*TensorNode]{}
+	for _, n := range inputs {
+		isInput[n] = true
+	}
+	for _, n := range g.Schedule() {
+		if !isInput[n] {
+			Append(&g.Intermediates, n)
+		}
+	}
+	return g
+}
+
+func (g *TensorGraph) Schedule() [](*TensorNode) {
+	if g.forwardSchedule == nil {
+		g.forwardSchedule = topologicalOrder(g.Inputs, func(n *TensorNode) [](*TensorNode) {
+			return n.Outputs
+		})
+	}
+	return g.forwardSchedule
+}
+
+func (g *TensorGraph) ReverseSchedule() [](*TensorNode) {
+	if g.reverseSchedule == nil {
+		g.reverseSchedule = topologicalOrder(g.Outputs, func(n *TensorNode) [](*TensorNode) {
+			return n.Inputs
+		})
+	}
+	return g.reverseSchedule
+}
+
+func (g *TensorGraph) SetInputs(vals []*Tensor) (err error) {
+	if len(vals) != len(g.Inputs) {
+		err = fmt.Errorf("error list of values must match list of inputs")
+		return
+	}
+	for i, val := range vals {
+		if !equalShapes(val.Shape, g.Inputs[i].Val.Shape) {
+			err = fmt.Errorf("error input %s expects shape %v, got %v", g.Inputs[i].Label, g.Inputs[i].Val.Shape, val.Shape)
+			return
+		}
+	}
+	for i, val := range vals {
+		g.Inputs[i].Val = val
+	}
+	return
+}
+
+func (g *TensorGraph) Forward(inputValues []*Tensor) (err error) {
+	err = g.SetInputs(inputValues)
+	if err != nil {
+		return
+	}
+	for _, n := range g.Schedule() {
+		err = n.ComputeVal()
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Backprop sets the gradient of each output to the matching upstream gradient
+// and accumulates gradients into every node, like Graph.Backprop.
+func (g *TensorGraph) Backprop(upstreamGrads []*Tensor) (err error) {
+	if len(upstreamGrads) != len(g.Outputs) {
+		err = fmt.Errorf("error list of upstream gradients must match list of outputs")
+		return
+	}
+	for i, out := range g.Outputs {
+		if !equalShapes(upstreamGrads[i].Shape, out.Val.Shape) {
+			err = fmt.Errorf("error output %s has shape %v, got gradient of shape %v", out.Label, out.Val.Shape, upstreamGrads[i].Shape)
+			return
+		}
+	}
+	for i, out := range g.Outputs {
+		out.Grad, err = NewTensor(upstreamGrads[i].Values(), out.Val.Shape...)
+		if err != nil {
+			return
+		}
+	}
+	for _, n := range g.ReverseSchedule() {
+		err = n.ComputeGrad()
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (g *TensorGraph) ZeroGrad() {
+	for _, list := range [][](*TensorNode){g.Inputs, g.Intermediates, g.Outputs} {
+		for _, n := range list {
+			n.Grad = nil
+		}
+	}
+}
+
+func equalShapes(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
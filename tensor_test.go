@@ -0,0 +1,153 @@
+package nngo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tensor(data []float64, shape ...int) *Tensor {
+	t, err := NewTensor(data, shape...)
+	Panic(err)
+	return t
+}
+
+func TestTensor1(t *testing.T) {
+	a := tensor([]float64{1, 2, 3, 4, 5, 6}, 2, 3)
+	assert.Equal(t, []int{3, 1}, a.Strides)
+	assert.Equal(t, 6.0, a.At(1, 2))
+	assert.True(t, a.IsContiguous())
+
+	at, err := a.Transpose()
+	Panic(err)
+	assert.Equal(t, []int{3, 2}, at.Shape)
+	assert.Equal(t, []float64{1, 4, 2, 5, 3, 6}, at.Values())
+	assert.False(t, at.IsContiguous())
+
+	r, err := at.Reshape(-1)
+	Panic(err)
+	assert.Equal(t, []int{6}, r.Shape)
+	assert.Equal(t, []float64{1, 4, 2, 5, 3, 6}, r.Values())
+
+	s, err := a.Slice(1, 1, 3)
+	Panic(err)
+	assert.Equal(t, []float64{2, 3, 5, 6}, s.Values())
+	s.Set(10, 0, 0)
+	assert.Equal(t, 10.0, a.At(0, 1))
+
+	_, err = a.Reshape(4, -1)
+	assert.Error(t, err)
+	_, err = a.Slice(0, 1, 3)
+	assert.Error(t, err)
+	_, err = a.Transpose(0, 0)
+	assert.Error(t, err)
+	_, err = NewTensor([]float64{1, 2}, 3)
+	assert.Error(t, err)
+
+	// -1 cannot be inferred next to a dimension of size 0
+	_, err = Zeros(0, 3).Reshape(0, -1)
+	assert.Error(t, err)
+
+	// indices are checked against their own dimension, not just the data
+	assert.Panics(t, func() { a.At(0, 3) })
+	assert.Panics(t, func() { a.At(2, 0) })
+	assert.Panics(t, func() { a.At(-1, 1) })
+	assert.Panics(t, func() { a.At(1) })
+	assert.Panics(t, func() { s.Set(1, 0, 2) })
+}
+
+func TestTensor2(t *testing.T) {
+	shape, err := BroadcastShapes([]int{2, 1, 3}, []int{4, 1}, []int{})
+	Panic(err)
+	assert.Equal(t, []int{2, 4, 3}, shape)
+
+	_, err = BroadcastShapes([]int{2, 3}, []int{3, 2})
+	assert.Error(t, err)
+
+	add, _ := LookupOperator(Add)
+	a := tensor([]float64{1, 2, 3, 4, 5, 6}, 2, 3)
+	b := tensor([]float64{10, 20, 30}, 3)
+	c := tensor([]float64{100, 200}, 2, 1)
+	sum, err := Elementwise(add, 0, a, b, c)
+	Panic(err)
+	assert.Equal(t, []int{2, 3}, sum.Shape)
+	assert.Equal(t, []float64{111, 122, 133, 214, 225, 236}, sum.Values())
+
+	rows, err := a.Sum(0)
+	Panic(err)
+	assert.Equal(t, []float64{5, 7, 9}, rows.Values())
+	cols, err := a.Mean(1)
+	Panic(err)
+	assert.Equal(t, []float64{2, 5}, cols.Values())
+	maxes, err := tensor([]float64{-3, -1, -2, -5}, 2, 2).Max(1)
+	Panic(err)
+	assert.Equal(t, []float64{-1, -2}, maxes.Values())
+
+	_, err = a.Sum(2)
+	assert.Error(t, err)
+}
+
+// f(x, w, b) = sum(mean(relu(x * w + b), 0)), with w broadcast over rows
+// and b broadcast over everything
+func TestTensorGraph1(t *testing.T) {
+	x := TensorInput("x", 2, 3)
+	w := TensorInput("w", 3)
+	b := TensorInput("b")
+	xw := TensorElementwise("xw", Multiply, 0, x, w)
+	z := TensorElementwise("z", Add, 0, xw, b)
+	a := TensorElementwise("a", Relu, 0, z)
+	m := TensorMean("m", a, 0)
+	f := TensorSum("f", m, 0)
+
+	graph := NewTensorGraph([](*TensorNode){x, w, b}, [](*TensorNode){f})
+	assert.Len(t, graph.Intermediates, 5)
+
+	err := graph.Forward([]*Tensor{
+		tensor([]float64{1, -2, 3, 4, 5, -6}, 2, 3),
+		tensor([]float64{1, 2, 3}, 3),
+		tensor([]float64{-1}),
+	})
+	Panic(err)
+	// z = [[0, -5, 8], [3, 9, -19]]
+	assert.Equal(t, []float64{0, -5, 8, 3, 9, -19}, z.Val.Values())
+	assert.Equal(t, []float64{1.5, 4.5, 4}, m.Val.Values())
+	assert.Equal(t, 10.0, f.Val.At())
+
+	err = graph.Backprop([]*Tensor{Full(1)})
+	Panic(err)
+	assert.Equal(t, []float64{0, 0, 1.5, 0.5, 1, 0}, x.Grad.Values())
+	assert.Equal(t, []float64{2, 2.5, 1.5}, w.Grad.Values())
+	assert.Equal(t, []float64{1.5}, b.Grad.Values())
+
+	assert.Error(t, graph.Forward([]*Tensor{Zeros(3, 2), Zeros(3), Zeros()}))
+	assert.Error(t, graph.Backprop([]*Tensor{Zeros(1)}))
+}
+
+// f(x) = sum(transpose(reshape(x))[1:3] * c)
+func TestTensorGraph2(t *testing.T) {
+	x := TensorInput("x", 6)
+	c := TensorInput("c", 2, 2)
+	r := TensorReshape("r", x, 2, 3)
+	tr := TensorTranspose("t", r)
+	s := TensorSlice("s", tr, 0, 1, 3)
+	p := TensorElementwise("p", Multiply, 0, s, c)
+	f := TensorSum("f", TensorSum("rows", p, 0), 0)
+
+	graph := NewTensorGraph([](*TensorNode){x, c}, [](*TensorNode){f})
+	err := graph.Forward([]*Tensor{
+		tensor([]float64{1, 2, 3, 4, 5, 6}, 6),
+		tensor([]float64{1, 10, 100, 1000}, 2, 2),
+	})
+	Panic(err)
+	// s = [[2, 5], [3, 6]]
+	assert.Equal(t, []float64{2, 5, 3, 6}, s.Val.Values())
+	assert.Equal(t, 2.0+50+300+6000, f.Val.At())
+
+	err = graph.Backprop([]*Tensor{Full(1)})
+	Panic(err)
+	assert.Equal(t, []float64{0, 1, 100, 0, 10, 1000}, x.Grad.Values())
+	assert.Equal(t, []float64{2, 5, 3, 6}, c.Grad.Values())
+
+	graph.ZeroGrad()
+	assert.Nil(t, x.Grad)
+}
@@ -1,9 +1,8 @@
 package nngo
 
 import (
+	"errors"
 	"fmt"
-	"math"
-	"math/rand"
 )
 
 type Op string
@@ -15,8 +14,27 @@ const (
 	Exp        Op = "exp"
 	Dot        Op = "dot"
 	Reciprocal Op = "reciprocal"
+	Log        Op = "log"
+	Pow        Op = "pow"
+	Sqrt       Op = "sqrt"
+	Tanh       Op = "tanh"
+	Sigmoid    Op = "sigmoid"
+	Softplus   Op = "softplus"
+	Gelu       Op = "gelu"
+	Elu        Op = "elu"
+	LeakyRelu  Op = "leaky-relu"
+	Abs        Op = "abs"
+	Huber      Op = "huber"
+	XLogY      Op = "xlogy"
+	Maximum    Op = "max"
+
+	SoftMaxCrossEntropy Op = "softmax-cross-entropy"
 )
 
+// ErrDomain is returned by Graph.Forward when an op is evaluated outside of
+// the domain it is defined on, e.g. the log of a non-positive value.
+var ErrDomain = errors.New("error value outside of op domain")
+
 type Node struct {
 	Label   string
 	Op      Op
@@ -24,6 +42,12 @@ type Node struct {
 	Outputs [](*Node)
 	Val     float64
 	Grad    float64
+	// Tangent is the directional derivative of Val set by Graph.JVP, which
+	// makes (Val, Tangent) a dual number.
+	Tangent float64
+	// Param holds a constant used by some ops, e.g. the exponent of a Pow
+	// node with a single input.
+	Param float64
 }
 
 func (n *Node) IsOutputSymbol() bool {
@@ -34,71 +58,57 @@ func (n *Node) IsInputSymbol() bool {
 	return len(n.Inputs) == 0
 }
 
-func (n *Node) ComputeGrad() {
-	switch n.Op {
-	case Add:
-		for _, inp := range n.Inputs {
-			inp.Grad += n.Grad
-		}
-	case Multiply:
-		for _, inp := range n.Inputs {
-			if inp.Val != 0 {
-				inp.Grad += (n.Grad * n.Val) / (inp.Val)
-			}
-		}
-	case Relu:
-		inp := n.Inputs[0]
-		if inp.Val > 0 {
-			inp.Grad += n.Grad
-		}
-	case Exp:
-		inp := n.Inputs[0]
-		inp.Grad += n.Grad * n.Val
-	case Dot:
-		d := len(n.Inputs) / 2
-		for i := range n.Inputs {
-			if i < d {
-				n.Inputs[i].Grad += n.Grad * n.Inputs[i+d].Val
-			} else {
-				n.Inputs[i].Grad += n.Grad * n.Inputs[i-d].Val
-			}
-		}
-	case Reciprocal:
-		n.Inputs[0].Grad += n.Grad * -1 * n.Val * n.Val
-	case "":
-		if n.IsOutputSymbol() {
+func (n *Node) inputVals() []float64 {
+	return Map(n.Inputs, func(n *Node) float64 {
+		return n.Val
+	})
+}
+
+func (n *Node) ComputeGrad() (err error) {
+	if n.Op == "" {
+		// output symbols, and the input symbols MergeTwo feeds from them,
+		// pass the gradient straight through to what feeds them
+		if len(n.Inputs) == 1 {
 			n.Inputs[0].Grad += n.Grad
 		}
+		return
+	}
+	op, ok := LookupOperator(n.Op)
+	if !ok {
+		err = fmt.Errorf("error unknown op %q at node %s", n.Op, n.Label)
+		return
+	}
+	vals := n.inputVals()
+	partials := make([]float64, len(vals))
+	op.Backward(vals, n.Val, n.Param, partials)
+	for i, inp := range n.Inputs {
+		inp.Grad += n.Grad * partials[i]
 	}
+	return
 }
 
-func (n *Node) ComputeVal() {
-	switch n.Op {
-	case Add:
-		n.Val = Sum(Map(n.Inputs, func(n *Node) float64 {
-			return n.Val
-		}))
-	case Multiply:
-		n.Val = Product(Map(n.Inputs, func(n *Node) float64 {
-			return n.Val
-		}))
-	case Relu:
-		n.Val = Max(0, n.Inputs[0].Val)
-	case Exp:
-		n.Val = math.Exp(n.Inputs[0].Val)
-	case Dot:
-		vals := Map(n.Inputs, func(n *Node) float64 {
-			return n.Val
-		})
-		d := len(vals) / 2
-		n.Val = DotProduct(vals[:d], vals[d:])
-	case Reciprocal:
-		n.Val = 1 / n.Inputs[0].Val
-	case "":
+func (n *Node) ComputeVal() (err error) {
+	if n.Op == "" {
 		if len(n.Inputs) > 0 {
 			n.Val = n.Inputs[0].Val
 		}
+		return
+	}
+	op, ok := LookupOperator(n.Op)
+	if !ok {
+		err = fmt.Errorf("error unknown op %q at node %s", n.Op, n.Label)
+		return
+	}
+	err = op.CheckArity(len(n.Inputs))
+	if err != nil {
+		err = fmt.Errorf("%w at node %s", err, n.Label)
+		return
+	}
+	n.Val, err = op.Forward(n.inputVals(), n.Param)
+	if err != nil {
+		err = fmt.Errorf("%w at node %s", err, n.Label)
 	}
+	return
 }
 
 func newNode(label string, op Op, inputs, outputs [](*Node)) Node {
@@ -134,6 +144,80 @@ func ExpNode(label string, outputs [](*Node), input *Node) Node {
 	return newNode(label, Exp, [](*Node){input}, outputs)
 }
 
+func LogNode(label string, outputs [](*Node), input *Node) Node {
+	return newNode(label, Log, [](*Node){input}, outputs)
+}
+
+// PowNode raises input to a constant exponent.
+func PowNode(label string, outputs [](*Node), input *Node, exponent float64) Node {
+	node := newNode(label, Pow, [](*Node){input}, outputs)
+	node.Param = exponent
+	return node
+}
+
+// PowVarNode raises base to the value of the exponent node, so gradients flow
+// into both of them.
+func PowVarNode(label string, outputs [](*Node), base, exponent *Node) Node {
+	return newNode(label, Pow, [](*Node){base, exponent}, outputs)
+}
+
+func SqrtNode(label string, outputs [](*Node), input *Node) Node {
+	return newNode(label, Sqrt, [](*Node){input}, outputs)
+}
+
+func TanhNode(label string, outputs [](*Node), input *Node) Node {
+	return newNode(label, Tanh, [](*Node){input}, outputs)
+}
+
+func SigmoidNode(label string, outputs [](*Node), input *Node) Node {
+	return newNode(label, Sigmoid, [](*Node){input}, outputs)
+}
+
+func SoftplusNode(label string, outputs [](*Node), input *Node) Node {
+	return newNode(label, Softplus, [](*Node){input}, outputs)
+}
+
+// GeluNode uses the exact form x * Φ(x), where Φ is the standard normal CDF.
+func GeluNode(label string, outputs [](*Node), input *Node) Node {
+	return newNode(label, Gelu, [](*Node){input}, outputs)
+}
+
+// EluNode computes x for x > 0 and alpha * (exp(x) - 1) otherwise.
+func EluNode(label string, outputs [](*Node), input *Node, alpha float64) Node {
+	node := newNode(label, Elu, [](*Node){input}, outputs)
+	node.Param = alpha
+	return node
+}
+
+// LeakyReluNode computes x for x > 0 and slope * x otherwise.
+func LeakyReluNode(label string, outputs [](*Node), input *Node, slope float64) Node {
+	node := newNode(label, LeakyRelu, [](*Node){input}, outputs)
+	node.Param = slope
+	return node
+}
+
+func AbsNode(label string, outputs [](*Node), input *Node) Node {
+	return newNode(label, Abs, [](*Node){input}, outputs)
+}
+
+// HuberNode computes x² / 2 for |x| <= delta and grows linearly beyond.
+func HuberNode(label string, outputs [](*Node), input *Node, delta float64) Node {
+	node := newNode(label, Huber, [](*Node){input}, outputs)
+	node.Param = delta
+	return node
+}
+
+// XLogYNode computes x * log(y), taken to be 0 when x is 0 whatever y is.
+func XLogYNode(label string, outputs [](*Node), x, y *Node) Node {
+	return newNode(label, XLogY, [](*Node){x, y}, outputs)
+}
+
+// MaximumNode takes the largest of its inputs, passing the gradient to the
+// first input holding it.
+func MaximumNode(label string, outputs, inputs [](*Node)) Node {
+	return newNode(label, Maximum, inputs, outputs)
+}
+
 func InputSymbol(label string, connectedTo [](*Node)) Node {
 	return Node{
 		Label:   label,
@@ -152,9 +236,22 @@ type Graph struct {
 	Inputs        [](*Node)
 	Outputs       [](*Node)
 	Intermediates [](*Node)
+	// Strict makes Forward and Backprop run Validate before evaluating.
+	Strict bool
+	// Subgraphs lists the nodes of each graph that Merge, Compose, Parallel
+	// or NewSequential combined into this one, which WriteDOT draws as
+	// clusters.
+	Subgraphs [][](*Node)
+
+	forwardSchedule [](*Node)
+	reverseSchedule [](*Node)
+	scheduledFor    listsKey
 }
 
+// MergeTwo feeds x.Outputs[i] into y.Inputs[i] by position. See Compose for
+// wiring by label.
 func MergeTwo(x, y Graph) Graph {
+	subgraphs := append(x.subgraphs(), y.subgraphs()...)
 	for i := range x.Outputs {
 		Append(&x.Outputs[i].Outputs, y.Inputs[i])
 		Append(&y.Inputs[i].Inputs, x.Outputs[i])
@@ -163,6 +260,8 @@ func MergeTwo(x, y Graph) Graph {
 	Append(&x.Intermediates, y.Inputs...)
 	Append(&x.Intermediates, y.Intermediates...)
 	x.Outputs = y.Outputs
+	x.Subgraphs = subgraphs
+	x.Invalidate()
 	return x
 }
 
@@ -174,6 +273,18 @@ func Merge(graphs []Graph) Graph {
 	return merged
 }
 
+// subgraphs returns the Subgraphs of g or, if it was not combined from
+// others, all of its listed nodes as a single subgraph.
+func (g *Graph) subgraphs() [][](*Node) {
+	if g.Subgraphs != nil {
+		return append([][](*Node){}, g.Subgraphs...)
+	}
+	nodes := append([](*Node){}, g.Inputs...)
+	Append(&nodes, g.Intermediates...)
+	Append(&nodes, g.Outputs...)
+	return [][](*Node){nodes}
+}
+
 func NewGraph(inputs, outputs, intermediates [](*Node)) Graph {
 	return Graph{
 		Inputs:        inputs,
@@ -182,19 +293,30 @@ func NewGraph(inputs, outputs, intermediates [](*Node)) Graph {
 	}
 }
 
+// SoftMax computes exp(x_i) / Σ exp(x_j) for n inputs. It subtracts the
+// largest input before exponentiating, which leaves the outputs unchanged but
+// keeps large inputs from overflowing.
 func SoftMax(n int, label string) Graph {
 	inputs := make([]Node, n)
+	shifted := make([]Node, n)
 	exps := make([]Node, n)
 	prods := make([]Node, n)
+	var largest, minusOne, negMax Node
 	var add Node
 	var reciprocal Node
 	outputs := make([]Node, n)
 
 	for i := range inputs {
-		inputs[i] = InputSymbol(fmt.Sprintf("%s-input-%d", label, i), [](*Node){&exps[i]})
+		inputs[i] = InputSymbol(fmt.Sprintf("%s-input-%d", label, i), [](*Node){&largest, &shifted[i]})
+	}
+	largest = MaximumNode(fmt.Sprintf("%s-max", label), [](*Node){&negMax}, ToPtrs(inputs))
+	minusOne = Node{Label: fmt.Sprintf("%s-minus-one", label), Outputs: [](*Node){&negMax}, Val: -1}
+	negMax = MultiplyNode(fmt.Sprintf("%s-negate", label), ToPtrs(shifted), [](*Node){&minusOne, &largest})
+	for i := range shifted {
+		shifted[i] = AddNode(fmt.Sprintf("%s-shift-%d", label, i), [](*Node){&exps[i]}, [](*Node){&inputs[i], &negMax})
 	}
 	for i := range exps {
-		exps[i] = ExpNode(fmt.Sprintf("%s-exp-%d", label, i), [](*Node){&add}, &inputs[i])
+		exps[i] = ExpNode(fmt.Sprintf("%s-exp-%d", label, i), [](*Node){&add, &prods[i]}, &shifted[i])
 	}
 	add = AddNode(fmt.Sprintf("%s-add", label), [](*Node){&reciprocal}, ToPtrs(exps))
 	reciprocal = ReciprocalNode(fmt.Sprintf("%s-reciprocal", label), ToPtrs(prods), &add)
@@ -213,6 +335,9 @@ func SoftMax(n int, label string) Graph {
 	Append(&intermediates, &add)
 	Append(&intermediates, &reciprocal)
 	Append(&intermediates, ToPtrs(prods)...)
+	// the shift comes last, keeping the nodes above where they always were
+	Append(&intermediates, &largest, &minusOne, &negMax)
+	Append(&intermediates, ToPtrs(shifted)...)
 	return NewGraph(ToPtrs(inputs), ToPtrs(outputs), intermediates)
 }
 
@@ -241,119 +366,6 @@ func (g *Graph) SetInputs(vals []float64) (err error) {
 	return
 }
 
-type Module struct {
-	Graph  Graph
-	Params [](*Node)
-}
-
-func (m *Module) Forward(inputValues []float64, optimizer *Optimizer) (err error) {
-	Append(&inputValues, optimizer.GetWeights()...)
-	err = m.Graph.Forward(inputValues)
-	return
-}
-
-func (m *Module) Backprop(upstreamGrads []float64, optimizer *Optimizer) {
-	m.Graph.Backprop(upstreamGrads)
-	grads := make([]float64, len(m.Params))
-	for i := range grads {
-		grads[i] = m.Params[i].Grad
-	}
-	optimizer.UpdateWeights(grads)
-}
-
-func NewLinear(n1 int, n2 int, label string) Module {
-	inputs := make([](*Node), n1)
-	weights := make([](*Node), n1*n2)
-	biases := make([](*Node), n2)
-	dots := make([](Node), n2)
-	dotPts := ToPtrs(dots)
-	outputs := make([](*Node), n2)
-
-	// initialize inputs
-	for i := 0; i < n1; i++ {
-		node := InputSymbol(fmt.Sprintf("%s-input-%d", label, i), dotPts)
-		inputs[i] = &node
-	}
-	unitNode := InputSymbol("unit", dotPts)
-	unitNode.Val = 1
-
-	// initialize weights
-	for i := 0; i < n2; i++ {
-		for j := 0; j < n1; j++ {
-			num := j + i*n1
-			node := InputSymbol(fmt.Sprintf("%s-weight-%d", label, num), [](*Node){&dots[i]})
-			weights[num] = &node
-		}
-	}
-
-	// initialize biases
-	for i := 0; i < n2; i++ {
-		node := InputSymbol(fmt.Sprintf("%s-bias-%d", label, i), [](*Node){&dots[i]})
-		biases[i] = &node
-	}
-
-	// initalize outputs
-	for i := 0; i < n2; i++ {
-		node := OutputSymbol(fmt.Sprintf("%s-output-%d", label, i), &dots[i])
-		outputs[i] = &node
-	}
-
-	// initialize dots
-	for i := 0; i < n2; i++ {
-		dotInputs := append(inputs, &unitNode)
-		Append(&dotInputs, weights[(n1*i):(n1+n1*i)]...)
-		Append(&dotInputs, biases[i])
-
-		dots[i] = DotNode(
-			fmt.Sprintf("%s-dot-%d", label, i),
-			[](*Node){outputs[i]},
-			dotInputs,
-		)
-	}
-
-	inps := inputs
-	for i := 0; i < n2; i++ {
-		Append(&inps, weights[(n1*i):(n1+n1*i)]...)
-		Append(&inps, biases[i])
-	}
-
-	return Module{
-		Graph:  NewGraph(inps, outputs, dotPts),
-		Params: inps[n1:],
-	}
-}
-
-type Optimizer struct {
-	NumParams    int
-	LearningRate float64
-	RandomSource *rand.Rand
-	params       []float64
-}
-
-func NewOptimizer(numParams int, learningRate float64, randSource *rand.Rand) Optimizer {
-	return Optimizer{
-		NumParams:    numParams,
-		LearningRate: learningRate,
-		RandomSource: randSource,
-	}
-}
-
-func (o *Optimizer) GetWeights() []float64 {
-	if len(o.params) == 0 {
-		// initialize
-		for i := 0; i < o.NumParams; i++ {
-			Append(&o.params, RandomFloat64(o.RandomSource, -1, 1))
-		}
-	}
-	return o.params
-}
-
-func (o *Optimizer) UpdateWeights(grads []float64) {
-	for i := 0; i < o.NumParams; i++ {
-		o.params[i] -= o.LearningRate * grads[i]
-	}
-}
-
 type Set[T comparable] map[T]bool
 
 type Stack[T any] struct {
@@ -2,6 +2,7 @@ package nngo
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 )
 
@@ -49,6 +50,19 @@ func Max[T int | float32 | float64](values ...T) (ret T) {
 	return
 }
 
+func Min[T int | float32 | float64](values ...T) (ret T) {
+	if len(values) == 0 {
+		Panic(fmt.Errorf("error at least one value should be passed to min"))
+	}
+	ret = values[0]
+	for i := 1; i < len(values); i += 1 {
+		if ret > values[i] {
+			ret = values[i]
+		}
+	}
+	return
+}
+
 func ToPtrs[T any](arr []T) (ret [](*T)) {
 	ret = make([](*T), len(arr))
 	for i := range arr {
@@ -83,3 +97,82 @@ func DotProduct(a, b []float64) (res float64) {
 func Append[T any](l *([]T), v ...T) {
 	*l = append(*l, v...)
 }
+
+// sigmoid avoids overflowing exp for large negative inputs.
+func sigmoid(x float64) float64 {
+	if x >= 0 {
+		return 1 / (1 + math.Exp(-x))
+	}
+	e := math.Exp(x)
+	return e / (1 + e)
+}
+
+// softplus computes log(1 + exp(x)) without overflowing for large inputs.
+func softplus(x float64) float64 {
+	return math.Max(x, 0) + math.Log1p(math.Exp(-math.Abs(x)))
+}
+
+// logSumExp computes log(Σ exp(x)) after subtracting the largest x, so it
+// neither overflows nor loses the largest terms.
+func logSumExp(xs []float64) float64 {
+	m := Max(xs...)
+	if math.IsInf(m, 0) {
+		return m
+	}
+	sum := 0.
+	for _, x := range xs {
+		sum += math.Exp(x - m)
+	}
+	return m + math.Log(sum)
+}
+
+func normalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+func normalPDF(x float64) float64 {
+	return math.Exp(-0.5*x*x) / math.Sqrt(2*math.Pi)
+}
+
+func contains[T comparable](arr []T, v T) bool {
+	for i := range arr {
+		if arr[i] == v {
+			return true
+		}
+	}
+	return false
+}
+
+// topologicalOrder returns every node reachable from roots through next,
+// ordered so that each node comes before the nodes next leads to.
+func topologicalOrder[T comparable](roots []T, next func(T) []T) (order []T) {
+	visited := Set[T]{}
+	sorted := Stack[T]{}
+	for _, n := range roots {
+		if !visited[n] {
+			topologicalVisit(n, next, visited, &sorted)
+		}
+	}
+
+	order = make([]T, 0, sorted.Size())
+	for {
+		n, empty := sorted.Pop()
+		if empty {
+			break
+		}
+		Append(&order, n)
+	}
+	return
+}
+
+// topologicalVisit is the depth-first step of topologicalOrder: it pushes n
+// onto sorted after every unvisited node reachable from it through next.
+func topologicalVisit[T comparable](n T, next func(T) []T, visited Set[T], sorted *Stack[T]) {
+	visited[n] = true
+	for _, m := range next(n) {
+		if !visited[m] {
+			topologicalVisit(m, next, visited, sorted)
+		}
+	}
+	sorted.Push(n)
+}
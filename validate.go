@@ -0,0 +1,166 @@
+package nngo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate checks the structure of the graph and returns every problem found,
+// joined into one error. It reports cycles, edges that are only recorded on
+// one of their ends, nodes reachable from the inputs that the graph does not
+// list, listed nodes that Forward never evaluates because no input or listed
+// constant leads to them, and nodes whose number of inputs their op cannot
+// take.
+func (g *Graph) Validate() error {
+	var errs []error
+	nodes := g.collect()
+
+	listed := Set[*Node]{}
+	for _, list := range [][](*Node){g.Inputs, g.Intermediates, g.Outputs} {
+		for _, n := range list {
+			listed[n] = true
+		}
+	}
+
+	for _, n := range nodes {
+		for _, out := range n.Outputs {
+			if !contains(out.Inputs, n) {
+				Append(&errs, fmt.Errorf("error %s lists %s as an output, but %s does not list it as an input", n.Label, out.Label, out.Label))
+			}
+		}
+		for _, inp := range n.Inputs {
+			if !contains(inp.Outputs, n) {
+				Append(&errs, fmt.Errorf("error %s lists %s as an input, but %s does not list it as an output", n.Label, inp.Label, inp.Label))
+			}
+		}
+		if err := checkArity(n); err != nil {
+			Append(&errs, err)
+		}
+	}
+
+	visited := Set[*Node]{}
+	sorted := Stack[*Node]{}
+	for _, inp := range g.Inputs {
+		if !visited[inp] {
+			g.TopologicalSort(inp, visited, &sorted, false)
+		}
+	}
+	for _, n := range nodes {
+		if visited[n] && !listed[n] {
+			Append(&errs, fmt.Errorf("error %s is reachable from the inputs but is not part of the graph", n.Label))
+		}
+	}
+
+	scheduled := Set[*Node]{}
+	for _, n := range topologicalOrder(g.leaves(), neighbors(false)) {
+		scheduled[n] = true
+	}
+	for _, list := range [][](*Node){g.Intermediates, g.Outputs} {
+		for _, n := range list {
+			if !scheduled[n] {
+				Append(&errs, fmt.Errorf("error %s is never evaluated, no input or listed constant leads to it", n.Label))
+			}
+		}
+	}
+
+	if cycle := findCycle(nodes); cycle != nil {
+		labels := Map(cycle, func(n *Node) string {
+			return n.Label
+		})
+		Append(&errs, fmt.Errorf("error graph has a cycle through %v", labels))
+	}
+
+	return errors.Join(errs...)
+}
+
+// collect returns every node connected to the graph's listed nodes, following
+// edges in both directions.
+func (g *Graph) collect() (nodes [](*Node)) {
+	seen := Set[*Node]{}
+	pending := Stack[*Node]{}
+	for _, list := range [][](*Node){g.Inputs, g.Intermediates, g.Outputs} {
+		for _, n := range list {
+			pending.Push(n)
+		}
+	}
+	for {
+		n, empty := pending.Pop()
+		if empty {
+			break
+		}
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		Append(&nodes, n)
+		for _, m := range n.Inputs {
+			pending.Push(m)
+		}
+		for _, m := range n.Outputs {
+			pending.Push(m)
+		}
+	}
+	return
+}
+
+func checkArity(n *Node) (err error) {
+	if n.Op == "" {
+		if len(n.Inputs) > 1 {
+			err = fmt.Errorf("error symbol %s has %d inputs, expected at most 1", n.Label, len(n.Inputs))
+		}
+		return
+	}
+	op, ok := LookupOperator(n.Op)
+	if !ok {
+		err = fmt.Errorf("error unknown op %q at node %s", n.Op, n.Label)
+		return
+	}
+	err = op.CheckArity(len(n.Inputs))
+	if err != nil {
+		err = fmt.Errorf("%w at node %s", err, n.Label)
+	}
+	return
+}
+
+// findCycle returns the nodes of a cycle along Outputs edges, or nil.
+func findCycle(nodes [](*Node)) [](*Node) {
+	const (
+		unvisited = iota
+		active
+		done
+	)
+	state := map[*Node]int{}
+	var path [](*Node)
+
+	var visit func(n *Node) [](*Node)
+	visit = func(n *Node) [](*Node) {
+		state[n] = active
+		Append(&path, n)
+		for _, out := range n.Outputs {
+			switch state[out] {
+			case active:
+				for i := range path {
+					if path[i] == out {
+						return append([](*Node){}, path[i:]...)
+					}
+				}
+			case unvisited:
+				if cycle := visit(out); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[n] = done
+		return nil
+	}
+
+	for _, n := range nodes {
+		if state[n] == unvisited {
+			if cycle := visit(n); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package nngo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate1(t *testing.T) {
+	linear := NewLinearInit(3, 2, "l", rand.New(rand.NewSource(42)))
+	assert.NoError(t, linear.Graph.Validate())
+
+	s := Merge([]Graph{SoftMax(3, "s1"), SoftMax(3, "s2")})
+	assert.NoError(t, s.Validate())
+
+	b := NewBuilder("f")
+	x := b.Input("x")
+	g := b.Build(b.Add(b.Mul(x, x), b.Const(1)))
+	assert.NoError(t, g.Validate())
+}
+
+// a = x + b, b = relu(a)
+func TestValidate2(t *testing.T) {
+	var a, b Node
+	x := InputSymbol("x", [](*Node){&a})
+	f := OutputSymbol("f", &b)
+	a = AddNode("a", [](*Node){&b}, [](*Node){&x, &b})
+	b = newNode("b", Relu, [](*Node){&a}, [](*Node){&a, &f})
+
+	graph := NewGraph([](*Node){&x}, [](*Node){&f}, [](*Node){&a, &b})
+	err := graph.Validate()
+	assert.ErrorContains(t, err, "cycle")
+
+	graph.Strict = true
+	assert.Error(t, graph.Forward([]float64{1}))
+	assert.Error(t, graph.Backprop([]float64{1}))
+}
+
+func TestValidate3(t *testing.T) {
+	var a, b, c Node
+	x := InputSymbol("x", [](*Node){&a})
+	y := InputSymbol("y", [](*Node){&b})
+	f := OutputSymbol("f", &c)
+	// y is missing from a's outputs, b is missing from the graph
+	a = ReluNode("a", &c, &x)
+	a.Inputs = [](*Node){&x, &y}
+	b = DotNode("b", [](*Node){&c}, [](*Node){&y})
+	c = AddNode("c", [](*Node){&f}, [](*Node){&a, &b})
+
+	graph := NewGraph([](*Node){&x, &y}, [](*Node){&f}, [](*Node){&a, &c})
+	err := graph.Validate()
+	assert.ErrorContains(t, err, "a lists y as an input, but y does not list it as an output")
+	assert.ErrorContains(t, err, "b is reachable from the inputs but is not part of the graph")
+	assert.ErrorContains(t, err, "expected 1 inputs, got 2 at node a")
+	assert.ErrorContains(t, err, "dot expects a positive even number of inputs, got 1 at node b")
+	assert.NotContains(t, err.Error(), "cycle")
+}
+
+func TestValidate4(t *testing.T) {
+	s := SoftMax(2, "s")
+	assert.Error(t, s.Backprop([]float64{1}))
+}
+
+// f = x * exp(c), with a constant c the graph does not list
+func TestValidate5(t *testing.T) {
+	var c, e, m Node
+	x := InputSymbol("x", [](*Node){&m})
+	f := OutputSymbol("f", &m)
+	c = Node{Label: "c", Outputs: [](*Node){&e}}
+	e = ExpNode("e", [](*Node){&m}, &c)
+	m = MultiplyNode("m", [](*Node){&f}, [](*Node){&x, &e})
+
+	graph := NewGraph([](*Node){&x}, [](*Node){&f}, [](*Node){&e, &m})
+	assert.ErrorContains(t, graph.Validate(), "e is never evaluated")
+
+	graph = NewGraph([](*Node){&x}, [](*Node){&f}, [](*Node){&c, &e, &m})
+	assert.NoError(t, graph.Validate())
+}